@@ -0,0 +1,241 @@
+package changelog
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const (
+	archiveCmdStr = "archive"
+
+	beforeFlagStr = "before"
+
+	archiveDirpathFlagStr        = "archive-dirpath"
+	archiveDirpathFlagDefaultVal = "docs/changelog"
+
+	// Archives are per major version, e.g. '0.x.md'
+	archiveFilenameFormat = "%d.x.md"
+	archiveLabelFormat    = "%d.x"
+
+	archiveLabelRegexStr       = "^([0-9]+)\\.x$"
+	archiveLabelMajorParseBase = 10
+	archiveLabelMajorParseBits = 64
+)
+
+var archiveLabelRegex = regexp.MustCompile(archiveLabelRegexStr)
+
+var before string
+var archiveDirpath string
+
+var archiveCmd = &cobra.Command{
+	Use:   archiveCmdStr,
+	Short: "Moves old versions out of the changelog into per-major archive files",
+	Long: `Moves every released version older than the '--before' version out of the changelog and into an archive file per major version (e.g. '0.x.md'), and lists links to the archive files at the bottom of the changelog.
+The latest released version always stays in the changelog, since releasing needs it.
+Archiving more versions later on adds them to the existing archive files. 'kudet changelog lint' follows the links, so version order and gaps are still checked across the changelog and its archives.`,
+	Args: cobra.NoArgs,
+	RunE: runArchive,
+}
+
+type archiveFile struct {
+	filepath  string
+	major     uint64
+	changelog *changelog_ast.Changelog
+}
+
+func init() {
+	archiveCmd.Flags().StringVar(&before, beforeFlagStr, "", "Released versions older than this one will be archived (required)")
+	archiveCmd.Flags().StringVar(&archiveDirpath, archiveDirpathFlagStr, archiveDirpathFlagDefaultVal, "The directory the archive files are written to, relative to the current working directory")
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	if before == "" {
+		return stacktrace.NewError("The '--%s' flag is required", beforeFlagStr)
+	}
+	beforeVersion, err := semver.StrictNewVersion(before)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing '%s' as a semantic version", before)
+	}
+	changelog, err := readChangelog()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the changelog")
+	}
+
+	archiveFiles, err := archiveVersions(changelog, changelogFilepath, beforeVersion, archiveDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred archiving the versions older than '%s'", beforeVersion)
+	}
+	if len(archiveFiles) == 0 {
+		logrus.Infof("No released versions older than '%s' were found, so nothing was archived", beforeVersion)
+		return nil
+	}
+
+	// Archive files go first so that, if anything fails, no versions have been removed from the changelog yet
+	for _, archive := range archiveFiles {
+		if err := os.MkdirAll(path.Dir(archive.filepath), changelogDirpathMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred creating the directory of archive file '%s'", archive.filepath)
+		}
+		if err := os.WriteFile(archive.filepath, archive.changelog.Bytes(), changelogFileMode); err != nil {
+			return stacktrace.Propagate(err, "An error occurred writing archive file '%s'", archive.filepath)
+		}
+		logrus.Infof("Archived versions to '%s'", archive.filepath)
+	}
+	changelogFileInfo, err := os.Stat(changelogFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the file info of changelog '%s'", changelogFilepath)
+	}
+	if err := os.WriteFile(changelogFilepath, changelog.Bytes(), changelogFileInfo.Mode()); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing changelog '%s'", changelogFilepath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Removes the versions older than the given one from the changelog and links to the archive files they were added to
+// Nothing is written to disk; the returned archive files contain both the previously and the newly archived versions
+func archiveVersions(
+	changelog *changelog_ast.Changelog,
+	changelogFilepath string,
+	beforeVersion *semver.Version,
+	archiveDirpath string,
+) ([]*archiveFile, error) {
+	// Releasing needs the latest release in the changelog, so it's kept even if it's older than the given version
+	var latestReleasedVersion *changelog_ast.Version
+	for _, version := range changelog.GetReleasedVersions() {
+		if version.Semver != nil && (latestReleasedVersion == nil || version.Semver.GreaterThan(latestReleasedVersion.Semver)) {
+			latestReleasedVersion = version
+		}
+	}
+
+	archiveFilesByMajor := map[uint64]*archiveFile{}
+	var keptVersions []*changelog_ast.Version
+	for _, version := range changelog.Versions {
+		if version == latestReleasedVersion && version.Semver.LessThan(beforeVersion) {
+			logrus.Infof("Keeping version '%s' in the changelog even though it's older than '%s', since it's the latest release", version.Name, beforeVersion)
+		}
+		if version.IsUnreleased() || version.Semver == nil || version == latestReleasedVersion || !version.Semver.LessThan(beforeVersion) {
+			keptVersions = append(keptVersions, version)
+			continue
+		}
+		archive, found := archiveFilesByMajor[version.Semver.Major()]
+		if !found {
+			archiveFilepath := path.Join(archiveDirpath, fmt.Sprintf(archiveFilenameFormat, version.Semver.Major()))
+			archivedChangelog, err := readArchiveFile(archiveFilepath, changelog.Format)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred reading archive file '%s'", archiveFilepath)
+			}
+			archive = &archiveFile{
+				filepath:  archiveFilepath,
+				major:     version.Semver.Major(),
+				changelog: archivedChangelog,
+			}
+			archiveFilesByMajor[version.Semver.Major()] = archive
+		}
+		if archive.changelog.GetVersion(version.Name) != nil {
+			return nil, stacktrace.NewError("Version '%s' is already archived in '%s'", version.Name, archive.filepath)
+		}
+		archive.changelog.Versions = append(archive.changelog.Versions, version)
+	}
+	if len(archiveFilesByMajor) == 0 {
+		return nil, nil
+	}
+
+	var result []*archiveFile
+	for _, archive := range archiveFilesByMajor {
+		sort.SliceStable(archive.changelog.Versions, func(i, j int) bool {
+			return archive.changelog.Versions[i].Semver.GreaterThan(archive.changelog.Versions[j].Semver)
+		})
+		archive.changelog.Canonicalize()
+		result = append(result, archive)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].major > result[j].major
+	})
+
+	archiveLinks, err := getUpdatedArchiveLinks(changelog.GetArchiveLinks(), result, changelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred computing the links to the archive files")
+	}
+	changelog.Versions = keptVersions
+	changelog.SetArchiveLinks(archiveLinks)
+	return result, nil
+}
+
+// Archive files only have released versions, so an archive file that doesn't exist yet is the same as an empty one
+func readArchiveFile(archiveFilepath string, format changelog_ast.Format) (*changelog_ast.Changelog, error) {
+	archiveFileContents, err := os.ReadFile(archiveFilepath)
+	if os.IsNotExist(err) {
+		return changelog_ast.Parse([]byte{}, format), nil
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading archive file '%s'", archiveFilepath)
+	}
+	archivedChangelog := changelog_ast.Parse(archiveFileContents, format)
+	if len(archivedChangelog.GetUnreleasedVersions()) > 0 {
+		return nil, stacktrace.NewError("Archive file '%s' has an unreleased version, which only belongs in the changelog", archiveFilepath)
+	}
+	return archivedChangelog, nil
+}
+
+// Keeps the existing links and adds the ones to new archive files, with the newest majors first
+func getUpdatedArchiveLinks(existingLinks []*changelog_ast.ArchiveLink, archiveFiles []*archiveFile, changelogFilepath string) ([]*changelog_ast.ArchiveLink, error) {
+	result := existingLinks
+	linkedTargets := map[string]bool{}
+	for _, link := range existingLinks {
+		linkedTargets[path.Clean(link.Target)] = true
+	}
+
+	for _, archive := range archiveFiles {
+		target, err := filepath.Rel(path.Dir(changelogFilepath), archive.filepath)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the path of archive file '%s' relative to changelog '%s'", archive.filepath, changelogFilepath)
+		}
+		target = filepath.ToSlash(target)
+		if linkedTargets[target] {
+			continue
+		}
+		result = append(result, &changelog_ast.ArchiveLink{
+			LineNumber: 0,
+			Label:      fmt.Sprintf(archiveLabelFormat, archive.major),
+			Target:     target,
+		})
+		linkedTargets[target] = true
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		iMajor, isIMajorFound := getArchiveLinkMajor(result[i])
+		jMajor, isJMajorFound := getArchiveLinkMajor(result[j])
+		if isIMajorFound != isJMajorFound {
+			return isIMajorFound
+		}
+		return iMajor > jMajor
+	})
+	return result, nil
+}
+
+// Links whose label isn't like 'N.x' were added by hand, and don't have a major
+func getArchiveLinkMajor(link *changelog_ast.ArchiveLink) (uint64, bool) {
+	matches := archiveLabelRegex.FindStringSubmatch(link.Label)
+	if matches == nil {
+		return 0, false
+	}
+	major, err := strconv.ParseUint(matches[1], archiveLabelMajorParseBase, archiveLabelMajorParseBits)
+	if err != nil {
+		return 0, false
+	}
+	return major, true
+}
@@ -0,0 +1,169 @@
+package changelog
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+const archiveTestChangelogStr = `# TBD
+* Something unreleased
+
+# 1.1.0
+* Newer
+
+# 1.0.0
+* First stable
+
+# 0.2.0
+### Fixes
+* A fix
+
+# 0.1.0
+* Initial
+`
+
+func TestArchiveVersions(t *testing.T) {
+	dirpath := t.TempDir()
+	changelogFilepath := path.Join(dirpath, "docs", "changelog.md")
+	archiveDirpath := path.Join(dirpath, "docs", "changelog")
+	changelog := changelog_ast.Parse([]byte(archiveTestChangelogStr), changelog_ast.KudetFormat)
+
+	archiveFiles, err := archiveVersions(changelog, changelogFilepath, semver.MustParse("1.1.0"), archiveDirpath)
+	require.NoError(t, err)
+	require.Len(t, archiveFiles, 2)
+
+	require.Equal(t, path.Join(archiveDirpath, "1.x.md"), archiveFiles[0].filepath)
+	require.Equal(t, "# 1.0.0\n\n* First stable\n", string(archiveFiles[0].changelog.Bytes()))
+	require.Equal(t, path.Join(archiveDirpath, "0.x.md"), archiveFiles[1].filepath)
+	require.Equal(t, "# 0.2.0\n\n### Fixes\n* A fix\n\n# 0.1.0\n\n* Initial\n", string(archiveFiles[1].changelog.Bytes()))
+
+	require.Equal(t, `# TBD
+* Something unreleased
+
+# 1.1.0
+* Newer
+
+# Archived versions
+
+* [1.x](changelog/1.x.md)
+* [0.x](changelog/0.x.md)
+`, string(changelog.Bytes()))
+
+	// The archived changelog must still lint cleanly, including the gap between the changelog and its archives
+	writeArchiveFiles(t, archiveFiles)
+	reparsedChangelog := changelog_ast.Parse(changelog.Bytes(), changelog_ast.KudetFormat)
+	archivedChangelogs, archiveDiagnostics := readArchivedChangelogs(reparsedChangelog, changelogFilepath)
+	require.Empty(t, archiveDiagnostics)
+	require.Len(t, archivedChangelogs, 2)
+	require.Empty(t, lintChangelog(reparsedChangelog, changelogFilepath, changelog_ast.KudetFormat.GetKnownCategories(), archivedChangelogs))
+}
+
+func TestArchiveVersions_AddsToExistingArchives(t *testing.T) {
+	dirpath := t.TempDir()
+	changelogFilepath := path.Join(dirpath, "changelog.md")
+	archiveDirpath := path.Join(dirpath, "changelog")
+	changelog := changelog_ast.Parse([]byte(archiveTestChangelogStr), changelog_ast.KudetFormat)
+
+	archiveFiles, err := archiveVersions(changelog, changelogFilepath, semver.MustParse("0.2.0"), archiveDirpath)
+	require.NoError(t, err)
+	writeArchiveFiles(t, archiveFiles)
+
+	archiveFiles, err = archiveVersions(changelog, changelogFilepath, semver.MustParse("1.0.0"), archiveDirpath)
+	require.NoError(t, err)
+	require.Len(t, archiveFiles, 1)
+	require.Equal(t, "# 0.2.0\n\n### Fixes\n* A fix\n\n# 0.1.0\n\n* Initial\n", string(archiveFiles[0].changelog.Bytes()))
+	require.Equal(t, []*changelog_ast.ArchiveLink{{LineNumber: 12, Label: "0.x", Target: "changelog/0.x.md"}}, changelog_ast.Parse(changelog.Bytes(), changelog_ast.KudetFormat).GetArchiveLinks())
+
+	archiveFiles, err = archiveVersions(changelog, changelogFilepath, semver.MustParse("1.0.0"), archiveDirpath)
+	require.NoError(t, err)
+	require.Empty(t, archiveFiles)
+}
+
+func TestArchiveVersions_KeepsLatestRelease(t *testing.T) {
+	dirpath := t.TempDir()
+	changelogFilepath := path.Join(dirpath, "changelog.md")
+	archiveDirpath := path.Join(dirpath, "changelog")
+	changelog := changelog_ast.Parse([]byte(archiveTestChangelogStr), changelog_ast.KudetFormat)
+
+	archiveFiles, err := archiveVersions(changelog, changelogFilepath, semver.MustParse("9.0.0"), archiveDirpath)
+	require.NoError(t, err)
+	require.Len(t, archiveFiles, 2)
+	require.Equal(t, "# 1.0.0\n\n* First stable\n", string(archiveFiles[0].changelog.Bytes()))
+
+	// Releasing fails without a released version in the changelog
+	reparsedChangelog := changelog_ast.Parse(changelog.Bytes(), changelog_ast.KudetFormat)
+	require.Len(t, reparsedChangelog.GetReleasedVersions(), 1)
+	require.Equal(t, "1.1.0", reparsedChangelog.GetReleasedVersions()[0].Name)
+}
+
+func TestAddArchivedVersions(t *testing.T) {
+	changelog := readArchivedTestChangelog(t, archiveTestChangelogStr, "1.0.0")
+	require.Equal(t, []string{changelog_ast.VersionToBeReleasedPlaceholderStr, "1.1.0", "1.0.0", "0.2.0", "0.1.0"}, getVersionNames(changelog.Versions))
+
+	dirpath := t.TempDir()
+	brokenChangelog := changelog_ast.Parse([]byte("# TBD\n\n# 1.0.0\n* Stable\n\n# Archived versions\n\n* [0.x](changelog/0.x.md)\n"), changelog_ast.KudetFormat)
+	require.ErrorContains(t, addArchivedVersions(brokenChangelog, path.Join(dirpath, "changelog.md")), "Found 1 problem(s) with the archive files")
+}
+
+func TestLintChangelog_ArchiveProblems(t *testing.T) {
+	dirpath := t.TempDir()
+	changelogFilepath := path.Join(dirpath, "changelog.md")
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "0.x.md"), []byte("# 0.2.0\n* A fix\n\n# 0.1.0\n* Initial\n"), 0644))
+	changelog := changelog_ast.Parse([]byte(`# TBD
+* Something
+
+# 1.0.0
+* First stable
+
+# 0.2.0
+* Duplicated
+
+# Archived versions
+
+* [0.x](0.x.md)
+* [Ancient](ancient.md)
+`), changelog_ast.KudetFormat)
+
+	archivedChangelogs, archiveDiagnostics := readArchivedChangelogs(changelog, changelogFilepath)
+	require.Len(t, archiveDiagnostics, 1)
+	require.Equal(t, archiveLinkLintRule, archiveDiagnostics[0].Rule)
+	require.Equal(t, 13, archiveDiagnostics[0].LineNumber)
+
+	diagnostics := lintChangelog(changelog, changelogFilepath, changelog_ast.KudetFormat.GetKnownCategories(), archivedChangelogs)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, duplicateVersionLintRule, diagnostics[0].Rule)
+	require.Equal(t, path.Join(dirpath, "0.x.md"), diagnostics[0].Filepath)
+	require.Equal(t, 1, diagnostics[0].LineNumber)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func writeArchiveFiles(t *testing.T, archiveFiles []*archiveFile) {
+	for _, archive := range archiveFiles {
+		require.NoError(t, os.MkdirAll(path.Dir(archive.filepath), 0755))
+		require.NoError(t, os.WriteFile(archive.filepath, archive.changelog.Bytes(), 0644))
+	}
+}
+
+// Archives the versions of the changelog older than the given one to a temporary directory, and reads the result back with its archived versions
+func readArchivedTestChangelog(t *testing.T, changelogStr string, before string) *changelog_ast.Changelog {
+	dirpath := t.TempDir()
+	changelogFilepath := path.Join(dirpath, "changelog.md")
+	changelog := changelog_ast.Parse([]byte(changelogStr), changelog_ast.KudetFormat)
+	archiveFiles, err := archiveVersions(changelog, changelogFilepath, semver.MustParse(before), path.Join(dirpath, "changelog"))
+	require.NoError(t, err)
+	require.NotEmpty(t, archiveFiles)
+	writeArchiveFiles(t, archiveFiles)
+
+	result := changelog_ast.Parse(changelog.Bytes(), changelog_ast.KudetFormat)
+	require.NoError(t, addArchivedVersions(result, changelogFilepath))
+	return result
+}
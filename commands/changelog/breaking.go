@@ -0,0 +1,161 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+const (
+	breakingCmdStr = "breaking"
+
+	fromFlagStr = "from"
+	toFlagStr   = "to"
+)
+
+var fromVersionStr string
+var toVersionStr string
+var shouldOutputBreakingJson bool
+
+var breakingCmd = &cobra.Command{
+	Use:   breakingCmdStr,
+	Short: "Aggregates the breaking changes between two versions into a single upgrade document",
+	Long:  "Collects every breaking changes subsection of the versions after the '--from' version, up to and including the '--to' version, and prints them oldest first as a single migration document in which each change is attributed to the version that introduced it.",
+	Args:  cobra.NoArgs,
+	RunE:  runBreaking,
+}
+
+// The breaking changes subsections of a single version
+type versionBreakingChanges struct {
+	version  *changelog_ast.Version
+	sections []*changelog_ast.Section
+}
+
+type versionBreakingChangesJson struct {
+	Version string   `json:"version"`
+	Date    string   `json:"date,omitempty"`
+	Entries []string `json:"entries"`
+}
+
+func init() {
+	breakingCmd.Flags().StringVar(&fromVersionStr, fromFlagStr, "", "The version being upgraded from; its own breaking changes aren't included")
+	breakingCmd.Flags().StringVar(&toVersionStr, toFlagStr, "", "The version being upgraded to; its breaking changes are included")
+	breakingCmd.Flags().BoolVar(&shouldOutputBreakingJson, jsonFlagStr, jsonFlagDefaultVal, "If set, the breaking changes will be printed as JSON rather than markdown")
+}
+
+func runBreaking(cmd *cobra.Command, args []string) error {
+	if fromVersionStr == "" || toVersionStr == "" {
+		return stacktrace.NewError("Both the '--%s' and '--%s' flags must be provided", fromFlagStr, toFlagStr)
+	}
+	fromVersion, err := semver.StrictNewVersion(strings.TrimPrefix(fromVersionStr, versionPrefixToTrim))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing '%s' as a semantic version", fromVersionStr)
+	}
+	toVersion, err := semver.StrictNewVersion(strings.TrimPrefix(toVersionStr, versionPrefixToTrim))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing '%s' as a semantic version", toVersionStr)
+	}
+	if !toVersion.GreaterThan(fromVersion) {
+		return stacktrace.NewError("The version being upgraded to, '%s', must be greater than the version being upgraded from, '%s'", toVersion.String(), fromVersion.String())
+	}
+
+	changelog, err := readChangelogWithArchives()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the changelog and its archives")
+	}
+	allBreakingChanges := getBreakingChanges(changelog, fromVersion, toVersion)
+
+	if shouldOutputBreakingJson {
+		allBreakingChangesJson := []*versionBreakingChangesJson{}
+		for _, breakingChanges := range allBreakingChanges {
+			entries := []string{}
+			for _, section := range breakingChanges.sections {
+				for _, entry := range section.Entries() {
+					entries = append(entries, section.GetEntryText(entry))
+				}
+			}
+			allBreakingChangesJson = append(allBreakingChangesJson, &versionBreakingChangesJson{
+				Version: breakingChanges.version.Name,
+				Date:    breakingChanges.version.Date,
+				Entries: entries,
+			})
+		}
+		serializedJson, err := json.MarshalIndent(allBreakingChangesJson, "", "  ")
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred serializing the breaking changes to JSON")
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(serializedJson))
+		return nil
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), renderBreakingChangesMarkdown(allBreakingChanges, fromVersion, toVersion))
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Returns the breaking changes of the versions in (fromVersion, toVersion], oldest version first
+func getBreakingChanges(changelog *changelog_ast.Changelog, fromVersion *semver.Version, toVersion *semver.Version) []*versionBreakingChanges {
+	var result []*versionBreakingChanges
+	releasedVersions := changelog.GetReleasedVersions()
+	for idx := len(releasedVersions) - 1; idx >= 0; idx-- {
+		version := releasedVersions[idx]
+		if version.Semver == nil || !version.Semver.GreaterThan(fromVersion) || version.Semver.GreaterThan(toVersion) {
+			continue
+		}
+		var breakingChangesSections []*changelog_ast.Section
+		for _, section := range version.Sections {
+			if section.IsBreakingChanges() && section.HasContent() {
+				breakingChangesSections = append(breakingChangesSections, section)
+			}
+		}
+		if len(breakingChangesSections) == 0 {
+			continue
+		}
+		result = append(result, &versionBreakingChanges{
+			version:  version,
+			sections: breakingChangesSections,
+		})
+	}
+	return result
+}
+
+func renderBreakingChangesMarkdown(allBreakingChanges []*versionBreakingChanges, fromVersion *semver.Version, toVersion *semver.Version) string {
+	title := fmt.Sprintf("# Breaking changes when upgrading from %s to %s", fromVersion.String(), toVersion.String())
+	if len(allBreakingChanges) == 0 {
+		return fmt.Sprintf("%s\n\nThere are no breaking changes.", title)
+	}
+
+	blocks := []string{title}
+	for _, breakingChanges := range allBreakingChanges {
+		var lines []string
+		header := fmt.Sprintf("## Introduced in %s", breakingChanges.version.Name)
+		if breakingChanges.version.Date != "" {
+			header = fmt.Sprintf("%s (%s)", header, breakingChanges.version.Date)
+		}
+		lines = append(lines, header)
+		for _, section := range breakingChanges.sections {
+			lines = append(lines, trimEmptyLines(section.Lines)...)
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func trimEmptyLines(lines []string) []string {
+	startIdx, endIdx := 0, len(lines)
+	for startIdx < endIdx && strings.TrimSpace(lines[startIdx]) == "" {
+		startIdx++
+	}
+	for endIdx > startIdx && strings.TrimSpace(lines[endIdx-1]) == "" {
+		endIdx--
+	}
+	return lines[startIdx:endIdx]
+}
@@ -0,0 +1,82 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBreakingChangesRendersOldestFirst(t *testing.T) {
+	changelogStr := `# TBD
+### Breaking Changes
+* Unreleased break
+
+# 1.6.0
+### Breaking Changes
+* Break in 1.6.0
+
+# 1.5.0
+* Not a break
+
+# 1.2.0 (2026-01-02)
+### Features
+* A feature
+
+### Breaking Changes
+* Break in 1.2.0
+  with migration steps
+
+# 1.0.0
+### Breaking Changes
+* Break in 1.0.0
+`
+	changelog := changelog_ast.Parse([]byte(changelogStr), changelog_ast.KudetFormat)
+	fromVersion := semver.MustParse("1.0.0")
+	toVersion := semver.MustParse("1.6.0")
+
+	expectedMarkdown := `# Breaking changes when upgrading from 1.0.0 to 1.6.0
+
+## Introduced in 1.2.0 (2026-01-02)
+* Break in 1.2.0
+  with migration steps
+
+## Introduced in 1.6.0
+* Break in 1.6.0`
+	allBreakingChanges := getBreakingChanges(changelog, fromVersion, toVersion)
+	require.Equal(t, expectedMarkdown, renderBreakingChangesMarkdown(allBreakingChanges, fromVersion, toVersion))
+}
+
+func TestGetBreakingChangesWithNoneInRange(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte("# TBD\n\n# 1.1.0\n* Something\n"), changelog_ast.KudetFormat)
+	fromVersion := semver.MustParse("1.0.0")
+	toVersion := semver.MustParse("1.1.0")
+
+	allBreakingChanges := getBreakingChanges(changelog, fromVersion, toVersion)
+	require.Empty(t, allBreakingChanges)
+	require.Contains(t, renderBreakingChangesMarkdown(allBreakingChanges, fromVersion, toVersion), "There are no breaking changes.")
+}
+
+func TestGetBreakingChangesIncludesArchivedVersions(t *testing.T) {
+	changelog := readArchivedTestChangelog(t, `# TBD
+* Something
+
+# 1.0.0
+* Stable
+
+# 0.2.0
+### Breaking Changes
+* Archived break
+
+# 0.1.0
+* Initial
+`, "1.0.0")
+	fromVersion := semver.MustParse("0.1.0")
+	toVersion := semver.MustParse("1.0.0")
+
+	allBreakingChanges := getBreakingChanges(changelog, fromVersion, toVersion)
+	require.Len(t, allBreakingChanges, 1)
+	require.Equal(t, "0.2.0", allBreakingChanges[0].version.Name)
+	require.Contains(t, renderBreakingChangesMarkdown(allBreakingChanges, fromVersion, toVersion), "* Archived break")
+}
@@ -0,0 +1,86 @@
+package changelog
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+const (
+	changelogCmdStr = "changelog"
+
+	changelogFilepathFlagStr        = "changelog-filepath"
+	changelogFilepathFlagDefaultVal = "docs/changelog.md"
+	changelogFormatFlagStr          = "changelog-format"
+	changelogFormatFlagDefaultVal   = string(changelog_ast.KudetFormat)
+)
+
+var changelogFilepath string
+var changelogFormatStr string
+
+var ChangelogCmd = &cobra.Command{
+	Use:   changelogCmdStr,
+	Short: "Tools for working with a repo's changelog",
+}
+
+func init() {
+	ChangelogCmd.PersistentFlags().StringVar(&changelogFilepath, changelogFilepathFlagStr, changelogFilepathFlagDefaultVal, "The path to the changelog, relative to the current working directory")
+	ChangelogCmd.PersistentFlags().StringVar(&changelogFormatStr, changelogFormatFlagStr, changelogFormatFlagDefaultVal, "The format of the changelog ("+strings.Join(changelog_ast.GetAllFormatStrs(), "|")+")")
+
+	ChangelogCmd.AddCommand(lintCmd)
+	ChangelogCmd.AddCommand(fmtCmd)
+	ChangelogCmd.AddCommand(showCmd)
+	ChangelogCmd.AddCommand(breakingCmd)
+	ChangelogCmd.AddCommand(exportCmd)
+	ChangelogCmd.AddCommand(checkCmd)
+	ChangelogCmd.AddCommand(importCmd)
+	ChangelogCmd.AddCommand(archiveCmd)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func readChangelog() (*changelog_ast.Changelog, error) {
+	changelogFormat, err := changelog_ast.ParseFormat(changelogFormatStr)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the changelog format")
+	}
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred attempting to read changelog file at provided path. Are you sure '%s' exists?", changelogFilepath)
+	}
+	return changelog_ast.Parse(changelogFile, changelogFormat), nil
+}
+
+// Reads the changelog with the versions of the archive files it links to after its own, so archived versions can be looked up like any other
+// The result is only meant to be read, since writing it back would undo the archiving
+func readChangelogWithArchives() (*changelog_ast.Changelog, error) {
+	changelog, err := readChangelog()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the changelog")
+	}
+	if err := addArchivedVersions(changelog, changelogFilepath); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the archived versions of changelog '%s'", changelogFilepath)
+	}
+	return changelog, nil
+}
+
+// Archives are linked newest major first, and hold their versions newest first, so appending them keeps the versions in changelog order
+func addArchivedVersions(changelog *changelog_ast.Changelog, changelogFilepath string) error {
+	archivedChangelogs, archiveDiagnostics := readArchivedChangelogs(changelog, changelogFilepath)
+	if len(archiveDiagnostics) > 0 {
+		var problems []string
+		for _, diagnostic := range archiveDiagnostics {
+			problems = append(problems, diagnostic.Message)
+		}
+		return stacktrace.NewError("Found %d problem(s) with the archive files:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	for _, archived := range archivedChangelogs {
+		changelog.Versions = append(changelog.Versions, archived.changelog.Versions...)
+	}
+	return nil
+}
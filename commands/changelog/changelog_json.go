@@ -0,0 +1,56 @@
+package changelog
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"strings"
+)
+
+// Structured representation of changelog versions, so consumers don't have to re-parse the markdown
+type versionJson struct {
+	Version            string          `json:"version"`
+	Date               string          `json:"date,omitempty"`
+	IsUnreleased       bool            `json:"unreleased"`
+	HasBreakingChanges bool            `json:"breaking"`
+	Categories         []*categoryJson `json:"categories"`
+}
+
+type categoryJson struct {
+	// Empty for the entries directly under the version header
+	Title              string   `json:"title"`
+	HasBreakingChanges bool     `json:"breaking"`
+	Entries            []string `json:"entries"`
+}
+
+func newVersionJson(version *changelog_ast.Version) *versionJson {
+	categories := []*categoryJson{}
+	for _, section := range version.Sections {
+		entries := []string{}
+		for _, entry := range section.Entries() {
+			entries = append(entries, section.GetEntryText(entry))
+		}
+		if section.IsUntitled() && len(entries) == 0 {
+			continue
+		}
+		categories = append(categories, &categoryJson{
+			Title:              section.Title,
+			HasBreakingChanges: section.IsBreakingChanges(),
+			Entries:            entries,
+		})
+	}
+	return &versionJson{
+		Version:            version.Name,
+		Date:               version.Date,
+		IsUnreleased:       version.IsUnreleased(),
+		HasBreakingChanges: version.HasBreakingChanges(),
+		Categories:         categories,
+	}
+}
+
+// Renders the version as markdown, without the blank lines that separate it from the next version
+func renderVersionMarkdown(version *changelog_ast.Version) string {
+	lines := version.Lines()
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
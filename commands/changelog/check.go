@@ -0,0 +1,299 @@
+package changelog
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/path_globs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	checkCmdStr = "check"
+
+	baseFlagStr        = "base"
+	baseFlagDefaultVal = "origin/main"
+
+	ignoreFlagStr = "ignore"
+
+	fragmentsDirpathFlagStr        = "fragments-dirpath"
+	fragmentsDirpathFlagDefaultVal = "docs/changelog.d"
+
+	skipChangelogTrailerRegexStr = `(?im)^skip-changelog\s*(:.*)?$`
+)
+
+// Changes to these don't need a changelog entry unless other globs are given
+var ignoreFlagDefaultVal = []string{"**/*.md", "docs/**", ".github/**"}
+
+var skipChangelogTrailerRegex = regexp.MustCompile(skipChangelogTrailerRegexStr)
+
+var base string
+var ignoreGlobs []string
+var fragmentsDirpath string
+
+var checkCmd = &cobra.Command{
+	Use:   checkCmdStr,
+	Short: "Checks that the current branch updates the changelog",
+	Long: `Diffs the current branch against the point where it forked from the base, and fails if files outside the ignore globs were changed without either adding lines to the TBD section of the changelog or adding a changelog fragment file.
+A PR that legitimately doesn't need a changelog entry can opt out with a 'skip-changelog' trailer in any of its commit messages.`,
+	Args: cobra.NoArgs,
+	RunE: runCheck,
+}
+
+type changelogCheckResult struct {
+	skippingCommitHash     string
+	changedCodeFilepaths   []string
+	addedUnreleasedLines   []string
+	addedFragmentFilepaths []string
+}
+
+func init() {
+	checkCmd.Flags().StringVar(&base, baseFlagStr, baseFlagDefaultVal, "The revision the current branch will be merged into")
+	checkCmd.Flags().StringSliceVar(&ignoreGlobs, ignoreFlagStr, ignoreFlagDefaultVal, "Glob of files whose changes don't need a changelog entry, where '**' matches any number of directories (can be repeated)")
+	checkCmd.Flags().StringVar(&fragmentsDirpath, fragmentsDirpathFlagStr, fragmentsDirpathFlagDefaultVal, "The directory where adding a file counts as adding a changelog entry, relative to the root of the repository")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	changelogFormat, err := changelog_ast.ParseFormat(changelogFormatStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the changelog format")
+	}
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repository, err := git.PlainOpen(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to open the existing git repository. Make sure this is run from the root of a git repository.")
+	}
+
+	result, err := checkChangelogUpdated(repository, base, changelogFilepath, changelogFormat, ignoreGlobs, fragmentsDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred checking whether the changelog was updated against '%s'", base)
+	}
+
+	if result.skippingCommitHash != "" {
+		logrus.Infof("Skipping the changelog check because commit '%s' has a 'skip-changelog' trailer", result.skippingCommitHash)
+		return nil
+	}
+	if len(result.changedCodeFilepaths) == 0 {
+		logrus.Infof("No files outside of the ignore globs changed, so no changelog entry is needed")
+		return nil
+	}
+	if len(result.addedUnreleasedLines) > 0 {
+		logrus.Infof("Found '%d' lines added to the TBD section of changelog '%s'", len(result.addedUnreleasedLines), changelogFilepath)
+		return nil
+	}
+	if len(result.addedFragmentFilepaths) > 0 {
+		logrus.Infof("Found changelog fragments: %s", strings.Join(result.addedFragmentFilepaths, ", "))
+		return nil
+	}
+
+	for _, filepath := range result.changedCodeFilepaths {
+		fmt.Fprintln(cmd.OutOrStdout(), filepath)
+	}
+	return stacktrace.NewError(
+		"The files above changed against '%s' but nothing was added to the TBD section of changelog '%s' nor to '%s'; "+
+			"add a changelog entry, or add a 'skip-changelog' trailer to a commit message if the change doesn't need one",
+		base,
+		changelogFilepath,
+		fragmentsDirpath,
+	)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func checkChangelogUpdated(
+	repository *git.Repository,
+	baseRevision string,
+	changelogFilepath string,
+	changelogFormat changelog_ast.Format,
+	ignoreGlobs []string,
+	fragmentsDirpath string,
+) (*changelogCheckResult, error) {
+	headRef, err := repository.Head()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HEAD of the repository")
+	}
+	headCommit, err := repository.CommitObject(headRef.Hash())
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the HEAD commit '%s'", headRef.Hash())
+	}
+	baseHash, err := repository.ResolveRevision(plumbing.Revision(baseRevision))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred resolving base revision '%s'", baseRevision)
+	}
+	baseCommit, err := repository.CommitObject(*baseHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting base commit '%s'", baseHash)
+	}
+	mergeBases, err := headCommit.MergeBase(baseCommit)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the merge base of HEAD and '%s'", baseRevision)
+	}
+	if len(mergeBases) == 0 {
+		return nil, stacktrace.NewError("HEAD and '%s' have no common ancestor", baseRevision)
+	}
+	mergeBaseCommit := mergeBases[0]
+
+	result := &changelogCheckResult{
+		skippingCommitHash:     "",
+		changedCodeFilepaths:   nil,
+		addedUnreleasedLines:   nil,
+		addedFragmentFilepaths: nil,
+	}
+
+	skippingCommitHash, err := getSkippingCommitHash(headCommit, baseCommit, mergeBases)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred looking for a 'skip-changelog' trailer in the branch's commits")
+	}
+	result.skippingCommitHash = skippingCommitHash
+
+	mergeBaseTree, err := mergeBaseCommit.Tree()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the tree of merge base commit '%s'", mergeBaseCommit.Hash)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the tree of HEAD commit '%s'", headCommit.Hash)
+	}
+	changes, err := object.DiffTree(mergeBaseTree, headTree)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred diffing HEAD against merge base commit '%s'", mergeBaseCommit.Hash)
+	}
+
+	ignoreRegexes := path_globs.AllToRegexes(ignoreGlobs)
+	cleanChangelogFilepath := path.Clean(changelogFilepath)
+	cleanFragmentsDirpath := path.Clean(fragmentsDirpath)
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the action of a change")
+		}
+		filepath := change.To.Name
+		if action == merkletrie.Delete {
+			filepath = change.From.Name
+		}
+
+		if action == merkletrie.Insert && strings.HasPrefix(filepath, cleanFragmentsDirpath+"/") {
+			result.addedFragmentFilepaths = append(result.addedFragmentFilepaths, filepath)
+			continue
+		}
+		if filepath == cleanChangelogFilepath || path_globs.MatchesAny(filepath, ignoreRegexes) {
+			continue
+		}
+		result.changedCodeFilepaths = append(result.changedCodeFilepaths, filepath)
+	}
+
+	mergeBaseChangelogFile, err := getFileContentsOrEmpty(mergeBaseTree, cleanChangelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading changelog '%s' at merge base commit '%s'", cleanChangelogFilepath, mergeBaseCommit.Hash)
+	}
+	headChangelogFile, err := getFileContentsOrEmpty(headTree, cleanChangelogFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading changelog '%s' at HEAD commit '%s'", cleanChangelogFilepath, headCommit.Hash)
+	}
+	result.addedUnreleasedLines = getAddedUnreleasedLines(
+		changelog_ast.Parse(mergeBaseChangelogFile, changelogFormat),
+		changelog_ast.Parse(headChangelogFile, changelogFormat),
+	)
+	return result, nil
+}
+
+// Returns the hash of the first commit on the branch with a 'skip-changelog' trailer, or empty if there's none
+// The walk stops at the base and its merge bases with HEAD, so commits that are already on the base aren't considered
+func getSkippingCommitHash(headCommit *object.Commit, baseCommit *object.Commit, mergeBaseCommits []*object.Commit) (string, error) {
+	ignoredCommitHashes := []plumbing.Hash{baseCommit.Hash}
+	for _, mergeBaseCommit := range mergeBaseCommits {
+		ignoredCommitHashes = append(ignoredCommitHashes, mergeBaseCommit.Hash)
+	}
+	commitIter := object.NewCommitPreorderIter(headCommit, nil, ignoredCommitHashes)
+	defer commitIter.Close()
+
+	result := ""
+	err := commitIter.ForEach(func(commit *object.Commit) error {
+		if skipChangelogTrailerRegex.MatchString(getMessageTrailers(commit.Message)) {
+			result = commit.Hash.String()
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred iterating over the commits of the branch")
+	}
+	return result, nil
+}
+
+// Trailers are the last paragraph of a commit message
+func getMessageTrailers(message string) string {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return ""
+	}
+	return paragraphs[len(paragraphs)-1]
+}
+
+// Returns the non-empty lines of the TBD section of the new changelog that weren't already in the TBD section of the old one
+func getAddedUnreleasedLines(oldChangelog *changelog_ast.Changelog, newChangelog *changelog_ast.Changelog) []string {
+	oldLineCounts := map[string]int{}
+	for _, version := range oldChangelog.GetUnreleasedVersions() {
+		for _, line := range getUnreleasedContentLines(version) {
+			oldLineCounts[line]++
+		}
+	}
+
+	var result []string
+	for _, version := range newChangelog.GetUnreleasedVersions() {
+		for _, line := range getUnreleasedContentLines(version) {
+			if oldLineCounts[line] > 0 {
+				oldLineCounts[line]--
+				continue
+			}
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// Returns the entry lines of the version, leaving out blank lines and category headers which on their own don't describe any change
+func getUnreleasedContentLines(version *changelog_ast.Version) []string {
+	var result []string
+	for _, section := range version.Sections {
+		for _, line := range section.Lines {
+			trimmedLine := strings.TrimSpace(line)
+			if trimmedLine == "" {
+				continue
+			}
+			result = append(result, trimmedLine)
+		}
+	}
+	return result
+}
+
+func getFileContentsOrEmpty(tree *object.Tree, filepath string) ([]byte, error) {
+	file, err := tree.File(filepath)
+	if err == object.ErrFileNotFound {
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting file '%s' from the tree", filepath)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading file '%s' from the tree", filepath)
+	}
+	return []byte(contents), nil
+}
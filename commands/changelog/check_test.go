@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	checkTestBaseBranchName   = "base"
+	checkTestChangelogPath    = "docs/changelog.md"
+	checkTestFragmentsDirpath = "docs/changelog.d"
+)
+
+var checkTestIgnoreGlobs = []string{"**/*.md", ".github/**"}
+
+func TestCheckChangelogUpdated_NoEntry(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{"main.go": "package main // changed"}, "Change code")
+
+	result := runCheckTest(t, repository)
+	require.Equal(t, []string{"main.go"}, result.changedCodeFilepaths)
+	require.Empty(t, result.addedUnreleasedLines)
+	require.Empty(t, result.addedFragmentFilepaths)
+	require.Empty(t, result.skippingCommitHash)
+}
+
+func TestCheckChangelogUpdated_IgnoredFilesOnly(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{
+		"README.md":                "Changed",
+		".github/workflows/ci.yml": "on: push",
+	}, "Change docs")
+
+	result := runCheckTest(t, repository)
+	require.Empty(t, result.changedCodeFilepaths)
+}
+
+func TestCheckChangelogUpdated_TBDEntry(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{
+		"main.go":              "package main // changed",
+		checkTestChangelogPath: "# TBD\n* Existing entry\n* New entry\n\n# 0.1.0\n* Old entry\n",
+	}, "Change code")
+
+	result := runCheckTest(t, repository)
+	require.Equal(t, []string{"main.go"}, result.changedCodeFilepaths)
+	require.Equal(t, []string{"* New entry"}, result.addedUnreleasedLines)
+}
+
+func TestCheckChangelogUpdated_ReleasedEntryDoesntCount(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{
+		"main.go":              "package main // changed",
+		checkTestChangelogPath: "# TBD\n* Existing entry\n### Fixes\n\n# 0.1.0\n* Old entry\n* Sneaky entry\n",
+	}, "Change code")
+
+	result := runCheckTest(t, repository)
+	require.Empty(t, result.addedUnreleasedLines)
+}
+
+func TestCheckChangelogUpdated_Fragment(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{
+		"main.go":                        "package main // changed",
+		checkTestFragmentsDirpath + "/1": "* New entry",
+	}, "Change code")
+
+	result := runCheckTest(t, repository)
+	require.Equal(t, []string{"main.go"}, result.changedCodeFilepaths)
+	require.Equal(t, []string{checkTestFragmentsDirpath + "/1"}, result.addedFragmentFilepaths)
+}
+
+func TestCheckChangelogUpdated_SkipTrailer(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	skippingCommitHash := commitCheckTestFiles(t, repository, dirpath, map[string]string{"main.go": "package main // changed"}, "Change code\n\nskip-changelog: internal refactor\n")
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{"other.go": "package main"}, "Mention skip-changelog in the body\n\nThis isn't a trailer: skip-changelog")
+
+	result := runCheckTest(t, repository)
+	require.Equal(t, skippingCommitHash.String(), result.skippingCommitHash)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Creates a repo with a base branch, and HEAD on a branch forked from it
+func initCheckTestRepo(t *testing.T) (*git.Repository, string) {
+	dirpath := t.TempDir()
+	repository, err := git.PlainInit(dirpath, false)
+	require.NoError(t, err)
+	baseCommitHash := commitCheckTestFiles(t, repository, dirpath, map[string]string{
+		"main.go":              "package main",
+		"README.md":            "Readme",
+		checkTestChangelogPath: "# TBD\n* Existing entry\n\n# 0.1.0\n* Old entry\n",
+	}, "Initial commit")
+	baseBranchRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(checkTestBaseBranchName), baseCommitHash)
+	require.NoError(t, repository.Storer.SetReference(baseBranchRef))
+	return repository, dirpath
+}
+
+func commitCheckTestFiles(t *testing.T, repository *git.Repository, dirpath string, filesContents map[string]string, message string) plumbing.Hash {
+	worktree, err := repository.Worktree()
+	require.NoError(t, err)
+	for filepath, contents := range filesContents {
+		absFilepath := path.Join(dirpath, filepath)
+		require.NoError(t, os.MkdirAll(path.Dir(absFilepath), 0755))
+		require.NoError(t, os.WriteFile(absFilepath, []byte(contents), 0644))
+		_, err := worktree.Add(filepath)
+		require.NoError(t, err)
+	}
+	commitHash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@kurtosistech.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return commitHash
+}
+
+func runCheckTest(t *testing.T, repository *git.Repository) *changelogCheckResult {
+	result, err := checkChangelogUpdated(repository, checkTestBaseBranchName, checkTestChangelogPath, changelog_ast.KudetFormat, checkTestIgnoreGlobs, checkTestFragmentsDirpath)
+	require.NoError(t, err)
+	return result
+}
@@ -0,0 +1,209 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"html"
+	"html/template"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	exportCmdStr = "export"
+
+	exportFormatFlagStr        = "format"
+	exportFormatFlagDefaultVal = jsonExportFormat
+	jsonExportFormat           = "json"
+	htmlExportFormat           = "html"
+
+	outputFilepathFlagStr        = "output"
+	outputFilepathFlagDefaultVal = ""
+	outputFileMode               = 0644
+
+	unreleasedVersionAnchor = "unreleased"
+	versionAnchorPrefix     = "v"
+	htmlPageTitle           = "Changelog"
+)
+
+var (
+	// Only the inline markdown that's common in changelog entries gets rendered; everything else is shown as-is
+	inlineCodeRegex = regexp.MustCompile("`([^`]+)`")
+	inlineLinkRegex = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+
+	changelogHtmlTemplate = template.Must(template.New("changelog").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<nav>
+<ul>
+{{- range .Versions }}
+<li><a href="#{{ .Anchor }}">{{ .Name }}</a></li>
+{{- end }}
+</ul>
+</nav>
+{{- range .Versions }}
+<section id="{{ .Anchor }}">
+<h2><a href="#{{ .Anchor }}">{{ .Name }}</a>{{ if .Date }} <small>{{ .Date }}</small>{{ end }}{{ if .HasBreakingChanges }} <strong>breaking</strong>{{ end }}</h2>
+{{- range .Categories }}
+{{- if .Title }}
+<h3>{{ .Title }}</h3>
+{{- end }}
+<ul>
+{{- range .Entries }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+`))
+)
+
+var exportFormat string
+var outputFilepath string
+
+var exportCmd = &cobra.Command{
+	Use:   exportCmdStr,
+	Short: "Exports the whole changelog as JSON or as a static HTML page",
+	Long:  "Exports the whole changelog as a structured document (versions, dates, categories, entries and breaking flags) in JSON, or as a static HTML page with an anchor per version, so that release history can be consumed without re-parsing the markdown.",
+	Args:  cobra.NoArgs,
+	RunE:  runExport,
+}
+
+type changelogJson struct {
+	Versions []*versionJson `json:"versions"`
+}
+
+type changelogHtmlData struct {
+	Title    string
+	Versions []*versionHtmlData
+}
+
+type versionHtmlData struct {
+	Anchor             string
+	Name               string
+	Date               string
+	HasBreakingChanges bool
+	Categories         []*categoryHtmlData
+}
+
+type categoryHtmlData struct {
+	Title   string
+	Entries []template.HTML
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, exportFormatFlagStr, exportFormatFlagDefaultVal, fmt.Sprintf("The format to export the changelog in (%s|%s)", jsonExportFormat, htmlExportFormat))
+	exportCmd.Flags().StringVar(&outputFilepath, outputFilepathFlagStr, outputFilepathFlagDefaultVal, "The file to write the export to; if empty, it will be printed instead")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	changelog, err := readChangelogWithArchives()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the changelog and its archives")
+	}
+
+	var exported []byte
+	switch exportFormat {
+	case jsonExportFormat:
+		exported, err = exportChangelogJson(changelog)
+	case htmlExportFormat:
+		exported, err = exportChangelogHtml(changelog)
+	default:
+		return stacktrace.NewError("Unrecognized export format '%s'; valid formats are: %s, %s", exportFormat, jsonExportFormat, htmlExportFormat)
+	}
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred exporting the changelog as '%s'", exportFormat)
+	}
+
+	if outputFilepath == "" {
+		fmt.Fprint(cmd.OutOrStdout(), string(exported))
+		return nil
+	}
+	if err := os.WriteFile(outputFilepath, exported, outputFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the exported changelog to '%s'", outputFilepath)
+	}
+	logrus.Infof("Exported changelog to '%s'", outputFilepath)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func exportChangelogJson(changelog *changelog_ast.Changelog) ([]byte, error) {
+	exportedChangelog := &changelogJson{
+		Versions: []*versionJson{},
+	}
+	for _, version := range changelog.Versions {
+		exportedChangelog.Versions = append(exportedChangelog.Versions, newVersionJson(version))
+	}
+	result, err := json.MarshalIndent(exportedChangelog, "", "  ")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred serializing the changelog to JSON")
+	}
+	return append(result, '\n'), nil
+}
+
+func exportChangelogHtml(changelog *changelog_ast.Changelog) ([]byte, error) {
+	data := &changelogHtmlData{
+		Title:    htmlPageTitle,
+		Versions: nil,
+	}
+	for _, version := range changelog.Versions {
+		exportedVersion := newVersionJson(version)
+		versionData := &versionHtmlData{
+			Anchor:             getVersionAnchor(version),
+			Name:               exportedVersion.Version,
+			Date:               exportedVersion.Date,
+			HasBreakingChanges: exportedVersion.HasBreakingChanges,
+			Categories:         nil,
+		}
+		for _, category := range exportedVersion.Categories {
+			categoryData := &categoryHtmlData{
+				Title:   category.Title,
+				Entries: nil,
+			}
+			for _, entry := range category.Entries {
+				categoryData.Entries = append(categoryData.Entries, renderInlineMarkdown(entry))
+			}
+			versionData.Categories = append(versionData.Categories, categoryData)
+		}
+		data.Versions = append(data.Versions, versionData)
+	}
+
+	result := &bytes.Buffer{}
+	if err := changelogHtmlTemplate.Execute(result, data); err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred rendering the changelog HTML template")
+	}
+	return result.Bytes(), nil
+}
+
+func getVersionAnchor(version *changelog_ast.Version) string {
+	if version.IsUnreleased() {
+		return unreleasedVersionAnchor
+	}
+	return versionAnchorPrefix + version.Name
+}
+
+// Escapes the text, then renders inline code and links
+func renderInlineMarkdown(text string) template.HTML {
+	escapedText := html.EscapeString(strings.TrimSpace(text))
+	escapedText = inlineCodeRegex.ReplaceAllString(escapedText, "<code>$1</code>")
+	escapedText = inlineLinkRegex.ReplaceAllString(escapedText, `<a href="$2">$1</a>`)
+	return template.HTML(escapedText)
+}
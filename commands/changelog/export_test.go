@@ -0,0 +1,67 @@
+package changelog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportChangelogJson(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte(showTestChangelogStr), changelog_ast.KudetFormat)
+
+	exported, err := exportChangelogJson(changelog)
+	require.NoError(t, err)
+
+	parsed := &changelogJson{}
+	require.NoError(t, json.Unmarshal(exported, parsed))
+	require.Len(t, parsed.Versions, 5)
+
+	require.Equal(t, changelog_ast.VersionToBeReleasedPlaceholderStr, parsed.Versions[0].Version)
+	require.True(t, parsed.Versions[0].IsUnreleased)
+
+	require.Equal(t, "1.4.0", parsed.Versions[1].Version)
+	require.Equal(t, "2026-10-16", parsed.Versions[1].Date)
+	require.True(t, parsed.Versions[1].HasBreakingChanges)
+	require.Len(t, parsed.Versions[1].Categories, 1)
+	require.Equal(t, "Breaking Changes", parsed.Versions[1].Categories[0].Title)
+	require.Equal(t, []string{"A break\n  with more details"}, parsed.Versions[1].Categories[0].Entries)
+
+	require.False(t, parsed.Versions[2].HasBreakingChanges)
+}
+
+func TestExportChangelogHtml(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte(`# TBD
+* Uses <b>tags</b> and `+"`code`"+`
+
+# 1.0.0 - 2026-10-16
+* See [the docs](https://docs.kurtosis.com)
+`), changelog_ast.KudetFormat)
+
+	exported, err := exportChangelogHtml(changelog)
+	require.NoError(t, err)
+	exportedStr := string(exported)
+
+	require.Contains(t, exportedStr, `<section id="unreleased">`)
+	require.Contains(t, exportedStr, `<section id="v1.0.0">`)
+	require.Contains(t, exportedStr, `<li><a href="#v1.0.0">1.0.0</a></li>`)
+	require.Contains(t, exportedStr, `<small>2026-10-16</small>`)
+	require.Contains(t, exportedStr, `<li>Uses &lt;b&gt;tags&lt;/b&gt; and <code>code</code></li>`)
+	require.Contains(t, exportedStr, `<li>See <a href="https://docs.kurtosis.com">the docs</a></li>`)
+}
+
+func TestExportChangelogJsonIncludesArchivedVersions(t *testing.T) {
+	changelog := readArchivedTestChangelog(t, showTestChangelogStr, "1.3.0")
+
+	exported, err := exportChangelogJson(changelog)
+	require.NoError(t, err)
+
+	parsed := &changelogJson{}
+	require.NoError(t, json.Unmarshal(exported, parsed))
+	var versionNames []string
+	for _, version := range parsed.Versions {
+		versionNames = append(versionNames, version.Version)
+	}
+	require.Equal(t, []string{changelog_ast.VersionToBeReleasedPlaceholderStr, "1.4.0", "1.3.0", "1.2.0", "1.1.0"}, versionNames)
+}
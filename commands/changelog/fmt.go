@@ -0,0 +1,77 @@
+package changelog
+
+import (
+	"bytes"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+const (
+	fmtCmdStr = "fmt"
+
+	checkFlagStr        = "check"
+	checkFlagDefaultVal = false
+)
+
+var shouldOnlyCheckFormatting bool
+
+var fmtCmd = &cobra.Command{
+	Use:   fmtCmdStr,
+	Short: "Rewrites the changelog in canonical form",
+	Long:  "Rewrites the changelog in the canonical form of its format, normalizing header spelling, subsection levels, bullets, blank lines and trailing whitespace. Running it on an already-formatted changelog changes nothing.",
+	Args:  cobra.NoArgs,
+	RunE:  runFmt,
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&shouldOnlyCheckFormatting, checkFlagStr, checkFlagDefaultVal, "If set, the changelog won't be rewritten and the command will fail if it isn't already formatted, which is useful for CI")
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	changelogFormat, err := changelog_ast.ParseFormat(changelogFormatStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the changelog format")
+	}
+	if err := formatChangelogFile(changelogFilepath, changelogFormat, shouldOnlyCheckFormatting); err != nil {
+		return stacktrace.Propagate(err, "An error occurred formatting the changelog")
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Rewrites the changelog file in canonical form, or if only checking, fails if it isn't in canonical form without writing it
+func formatChangelogFile(changelogFilepath string, changelogFormat changelog_ast.Format, shouldOnlyCheck bool) error {
+	changelogFileInfo, err := os.Stat(changelogFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to retrieve file info for the changelog file at '%s'", changelogFilepath)
+	}
+	originalChangelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to read changelog file at provided path. Are you sure '%s' exists?", changelogFilepath)
+	}
+
+	changelog := changelog_ast.Parse(originalChangelogFile, changelogFormat)
+	changelog.Canonicalize()
+	formattedChangelogFile := changelog.Bytes()
+
+	if bytes.Equal(originalChangelogFile, formattedChangelogFile) {
+		logrus.Infof("Changelog '%s' is already formatted", changelogFilepath)
+		return nil
+	}
+	if shouldOnlyCheck {
+		return stacktrace.NewError("Changelog '%s' isn't formatted; run 'kudet changelog fmt' to format it", changelogFilepath)
+	}
+
+	if err := os.WriteFile(changelogFilepath, formattedChangelogFile, changelogFileInfo.Mode()); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the formatted changelog to '%s'", changelogFilepath)
+	}
+	logrus.Infof("Formatted changelog '%s'", changelogFilepath)
+	return nil
+}
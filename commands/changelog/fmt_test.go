@@ -0,0 +1,57 @@
+package changelog
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	unformattedFmtTestChangelogStr = "#TBD\n## Features\n- Something   \n\n\n# 0.1.0\n* Initial release\n"
+	formattedFmtTestChangelogStr   = "# TBD\n\n### Features\n* Something\n\n# 0.1.0\n\n* Initial release\n"
+)
+
+func TestFormatChangelogFile_CheckFailsWithoutWriting(t *testing.T) {
+	changelogFilepath := writeFmtTestChangelog(t, unformattedFmtTestChangelogStr)
+
+	err := formatChangelogFile(changelogFilepath, changelog_ast.KudetFormat, true)
+	require.ErrorContains(t, err, "isn't formatted")
+	require.Equal(t, unformattedFmtTestChangelogStr, readFmtTestChangelog(t, changelogFilepath), "Checking the formatting shouldn't rewrite the changelog")
+
+	formattedChangelogFilepath := writeFmtTestChangelog(t, formattedFmtTestChangelogStr)
+	require.NoError(t, formatChangelogFile(formattedChangelogFilepath, changelog_ast.KudetFormat, true))
+}
+
+func TestFormatChangelogFile_Rewrites(t *testing.T) {
+	changelogFilepath := writeFmtTestChangelog(t, unformattedFmtTestChangelogStr)
+	require.NoError(t, os.Chmod(changelogFilepath, 0600))
+
+	require.NoError(t, formatChangelogFile(changelogFilepath, changelog_ast.KudetFormat, false))
+	require.Equal(t, formattedFmtTestChangelogStr, readFmtTestChangelog(t, changelogFilepath))
+	changelogFileInfo, err := os.Stat(changelogFilepath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), changelogFileInfo.Mode().Perm(), "Formatting should keep the permissions of the changelog")
+
+	require.NoError(t, formatChangelogFile(changelogFilepath, changelog_ast.KudetFormat, true), "A formatted changelog should pass the check")
+	require.Error(t, formatChangelogFile(path.Join(t.TempDir(), "missing.md"), changelog_ast.KudetFormat, false))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func writeFmtTestChangelog(t *testing.T, changelogStr string) string {
+	changelogFilepath := path.Join(t.TempDir(), "changelog.md")
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte(changelogStr), 0644))
+	return changelogFilepath
+}
+
+func readFmtTestChangelog(t *testing.T, changelogFilepath string) string {
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	require.NoError(t, err)
+	return string(changelogFile)
+}
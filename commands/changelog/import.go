@@ -0,0 +1,169 @@
+package changelog
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/release_tags"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	importCmdStr = "import"
+
+	forceFlagStr        = "force"
+	forceFlagDefaultVal = false
+
+	stampReleaseDatesFlagStr        = "stamp-release-dates"
+	stampReleaseDatesFlagDefaultVal = false
+	releaseDateLayoutFlagStr        = "release-date-layout"
+	// Go reference time layout, see https://pkg.go.dev/time#pkg-constants
+	releaseDateLayoutFlagDefaultVal = "2006-01-02"
+
+	// The commits 'kudet release' makes don't describe any change of their own
+	releaseCommitMsgPrefix = "Finalize changes for release version"
+	noChangesEntry         = "No changes"
+
+	changelogFileMode    = 0644
+	changelogDirpathMode = 0755
+)
+
+var shouldOverwriteChangelog bool
+var shouldStampReleaseDates bool
+var releaseDateLayout string
+
+var importCmd = &cobra.Command{
+	Use:   importCmdStr,
+	Short: "Builds an initial changelog from the repo's release tags",
+	Long: `Builds an initial changelog for a repo that never had one, with a version for every X.Y.Z tag whose entries are the subjects of the commits between it and the previous tag.
+A TBD header is added on top so that 'kudet release' can be used right away. The generated entries are a starting point, and are worth editing by hand.`,
+	Args: cobra.NoArgs,
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&shouldOverwriteChangelog, forceFlagStr, forceFlagDefaultVal, "If set, an existing changelog will be overwritten")
+	importCmd.Flags().BoolVar(&shouldStampReleaseDates, stampReleaseDatesFlagStr, stampReleaseDatesFlagDefaultVal, "If set, every version header will get the date of its tagged commit (always done for changelog formats that require release dates)")
+	importCmd.Flags().StringVar(&releaseDateLayout, releaseDateLayoutFlagStr, releaseDateLayoutFlagDefaultVal, "The Go time layout used to render release dates")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	changelogFormat, err := changelog_ast.ParseFormat(changelogFormatStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the changelog format")
+	}
+	if _, err := os.Stat(changelogFilepath); err == nil && !shouldOverwriteChangelog {
+		return stacktrace.NewError("Changelog '%s' already exists; pass '--%s' to overwrite it", changelogFilepath, forceFlagStr)
+	}
+
+	currentWorkingDirpath, err := os.Getwd()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the current working directory.")
+	}
+	repository, err := git.PlainOpen(currentWorkingDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while attempting to open the existing git repository. Make sure this is run from the root of a git repository.")
+	}
+
+	dateLayout := ""
+	if shouldStampReleaseDates || changelogFormat.IsReleaseDateRequired() {
+		dateLayout = releaseDateLayout
+	}
+	changelog, err := importChangelog(repository, changelogFormat, dateLayout)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred building a changelog from the repository's release tags")
+	}
+
+	if err := os.MkdirAll(path.Dir(changelogFilepath), changelogDirpathMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred creating the directory of changelog '%s'", changelogFilepath)
+	}
+	if err := os.WriteFile(changelogFilepath, changelog.Bytes(), changelogFileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing changelog '%s'", changelogFilepath)
+	}
+	logrus.Infof("Wrote changelog '%s' with '%d' released versions", changelogFilepath, len(changelog.GetReleasedVersions()))
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// If the date layout is empty, version headers won't have dates
+func importChangelog(repository *git.Repository, changelogFormat changelog_ast.Format, dateLayout string) (*changelog_ast.Changelog, error) {
+	releaseTags, err := release_tags.GetReleaseTags(repository)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the release tags of the repository")
+	}
+	if len(releaseTags) == 0 {
+		logrus.Warnf("No X.Y.Z release tags were found, so the changelog will only have a TBD header")
+	}
+
+	// Tags are walked oldest first, so every commit gets attributed to the first release that shipped it
+	seenCommitHashes := map[plumbing.Hash]bool{}
+	var versionNames []string
+	versionEntries := map[string][]string{}
+	versionDates := map[string]string{}
+	for _, releaseTag := range releaseTags {
+		tagCommit, err := repository.CommitObject(releaseTag.CommitHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting commit '%s' of tag '%s'", releaseTag.CommitHash, releaseTag.Name)
+		}
+		entries, err := getNewCommitSubjects(tagCommit, seenCommitHashes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting the commits of tag '%s'", releaseTag.Name)
+		}
+		if len(entries) == 0 {
+			entries = []string{noChangesEntry}
+		}
+
+		versionName := releaseTag.Version.String()
+		versionNames = append(versionNames, versionName)
+		versionEntries[versionName] = entries
+		if dateLayout != "" {
+			versionDates[versionName] = tagCommit.Committer.When.Format(dateLayout)
+		}
+	}
+
+	changelog := changelog_ast.New(changelogFormat)
+	for idx := len(versionNames) - 1; idx >= 0; idx-- {
+		versionName := versionNames[idx]
+		if err := changelog.AppendReleasedVersion(versionName, versionDates[versionName], versionEntries[versionName]); err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred adding version '%s' to the changelog", versionName)
+		}
+	}
+	changelog.Canonicalize()
+	return changelog, nil
+}
+
+// Returns the subjects of the commits reachable from the given commit that haven't been seen yet, newest first, and marks them as seen
+func getNewCommitSubjects(commit *object.Commit, seenCommitHashes map[plumbing.Hash]bool) ([]string, error) {
+	var newCommits []*object.Commit
+	commitIter := object.NewCommitPreorderIter(commit, seenCommitHashes, nil)
+	defer commitIter.Close()
+	err := commitIter.ForEach(func(commit *object.Commit) error {
+		newCommits = append(newCommits, commit)
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred iterating over the commits of '%s'", commit.Hash)
+	}
+
+	var result []string
+	for _, newCommit := range newCommits {
+		seenCommitHashes[newCommit.Hash] = true
+		subject := strings.TrimSpace(strings.SplitN(newCommit.Message, "\n", 2)[0])
+		// Merge commits only repeat what the merged commits already say
+		if newCommit.NumParents() > 1 || subject == "" || strings.HasPrefix(subject, releaseCommitMsgPrefix) {
+			continue
+		}
+		result = append(result, subject)
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportChangelog(t *testing.T) {
+	repository, dirpath := initCheckTestRepo(t)
+	firstReleaseHash := commitCheckTestFiles(t, repository, dirpath, map[string]string{"main.go": "package main // 1"}, "Add the first feature")
+	_, err := repository.CreateTag("0.1.0", firstReleaseHash, nil)
+	require.NoError(t, err)
+	_, err = repository.CreateTag("v0.1.0", firstReleaseHash, nil)
+	require.NoError(t, err)
+
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{"main.go": "package main // 2"}, "Fix a bug\n\nWith some details")
+	secondReleaseHash := commitCheckTestFiles(t, repository, dirpath, map[string]string{"docs/changelog.md": "# TBD\n"}, "Finalize changes for release version '0.2.0'")
+	_, err = repository.CreateTag("0.2.0", secondReleaseHash, nil)
+	require.NoError(t, err)
+
+	_, err = repository.CreateTag("0.2.1", secondReleaseHash, nil)
+	require.NoError(t, err)
+	commitCheckTestFiles(t, repository, dirpath, map[string]string{"main.go": "package main // 3"}, "Unreleased work")
+
+	changelog, err := importChangelog(repository, changelog_ast.KudetFormat, "")
+	require.NoError(t, err)
+	require.Equal(t, `# TBD
+
+# 0.2.1
+
+* No changes
+
+# 0.2.0
+
+* Fix a bug
+
+# 0.1.0
+
+* Add the first feature
+* Initial commit
+`, string(changelog.Bytes()))
+
+	// The imported changelog must be one that releases can be cut from
+	reparsedChangelog := changelog_ast.Parse(changelog.Bytes(), changelog_ast.KudetFormat)
+	require.Len(t, reparsedChangelog.GetUnreleasedVersions(), 1)
+	require.Empty(t, lintChangelog(reparsedChangelog, checkTestChangelogPath, changelog_ast.KudetFormat.GetKnownCategories(), nil))
+}
+
+func TestImportChangelog_KeepAChangelog(t *testing.T) {
+	repository, _ := initCheckTestRepo(t)
+	initialCommitHash, err := repository.ResolveRevision(checkTestBaseBranchName)
+	require.NoError(t, err)
+	_, err = repository.CreateTag("1.0.0", *initialCommitHash, nil)
+	require.NoError(t, err)
+
+	changelog, err := importChangelog(repository, changelog_ast.KeepAChangelogFormat, "2006")
+	require.NoError(t, err)
+
+	releasedVersions := changelog.GetReleasedVersions()
+	require.Len(t, releasedVersions, 1)
+	require.NotEmpty(t, releasedVersions[0].Date)
+	require.Contains(t, string(changelog.Bytes()), "# Changelog\n")
+	require.Contains(t, string(changelog.Bytes()), "## [Unreleased]\n")
+	require.Contains(t, string(changelog.Bytes()), "- Initial commit\n")
+}
+
+func TestImportChangelog_NoTags(t *testing.T) {
+	repository, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+
+	changelog, err := importChangelog(repository, changelog_ast.KudetFormat, "")
+	require.NoError(t, err)
+	require.Equal(t, "# TBD\n", string(changelog.Bytes()))
+}
@@ -0,0 +1,262 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	lintCmdStr = "lint"
+
+	jsonFlagStr        = "json"
+	jsonFlagDefaultVal = false
+
+	allowedCategoryFlagStr = "allowed-category"
+
+	// Used for problems that concern the whole document rather than a specific line
+	documentLineNumber = 1
+
+	singleTBDLintRule            = "single-tbd"
+	versionOrderLintRule         = "version-order"
+	duplicateVersionLintRule     = "duplicate-version"
+	versionGapLintRule           = "version-gap"
+	unknownCategoryLintRule      = "unknown-category"
+	emptyReleasedSectionLintRule = "empty-released-section"
+	bulletStyleLintRule          = "bullet-style"
+	archiveLinkLintRule          = "archive-link"
+)
+
+var shouldOutputLintJson bool
+var extraAllowedCategories []string
+
+var lintCmd = &cobra.Command{
+	Use:   lintCmdStr,
+	Short: "Checks the changelog for problems",
+	Long: `Checks the changelog for problems that would otherwise only be found at release time, or not at all:
+- there must be exactly one TBD header, and it must be the first version header
+- released versions must be in strictly descending order, without duplicates or gaps
+- category subheaders must be known ones
+- released versions and their categories can't be empty
+- every entry must use the same bullet character
+Archive files linked from the changelog are read too, so that version order, duplicates and gaps are checked across the changelog and its archives.`,
+	Args: cobra.NoArgs,
+	RunE: runLint,
+}
+
+// A file that versions were moved to by 'kudet changelog archive'
+type archivedChangelog struct {
+	filepath  string
+	changelog *changelog_ast.Changelog
+}
+
+type lintDiagnostic struct {
+	Filepath   string `json:"file"`
+	LineNumber int    `json:"line"`
+	Rule       string `json:"rule"`
+	Message    string `json:"message"`
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&shouldOutputLintJson, jsonFlagStr, jsonFlagDefaultVal, "If set, problems will be printed as a JSON array rather than as 'file:line' diagnostics")
+	lintCmd.Flags().StringSliceVar(&extraAllowedCategories, allowedCategoryFlagStr, nil, "Category subheader to allow on top of the ones conventionally used by the changelog format (can be repeated)")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	changelog, err := readChangelog()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the changelog")
+	}
+
+	allowedCategories := append([]string{}, changelog.Format.GetKnownCategories()...)
+	allowedCategories = append(allowedCategories, extraAllowedCategories...)
+	archivedChangelogs, archiveDiagnostics := readArchivedChangelogs(changelog, changelogFilepath)
+	diagnostics := lintChangelog(changelog, changelogFilepath, allowedCategories, archivedChangelogs)
+	diagnostics = append(diagnostics, archiveDiagnostics...)
+
+	if shouldOutputLintJson {
+		// Always print an array, even when there are no problems, so consumers don't have to special-case null
+		if diagnostics == nil {
+			diagnostics = []*lintDiagnostic{}
+		}
+		diagnosticsJson, err := json.MarshalIndent(diagnostics, "", "  ")
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred serializing the changelog lint problems to JSON")
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(diagnosticsJson))
+	} else {
+		for _, diagnostic := range diagnostics {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s:%d: %s [%s]\n", diagnostic.Filepath, diagnostic.LineNumber, diagnostic.Message, diagnostic.Rule)
+		}
+	}
+
+	if len(diagnostics) > 0 {
+		return stacktrace.NewError("Found '%d' problems in changelog '%s'", len(diagnostics), changelogFilepath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Archived changelogs must be ordered like the links to them, and their versions are only checked for order, duplicates and gaps
+func lintChangelog(changelog *changelog_ast.Changelog, filepath string, allowedCategories []string, archivedChangelogs []*archivedChangelog) []*lintDiagnostic {
+	var result []*lintDiagnostic
+	addDiagnosticToFile := func(diagnosticFilepath string, lineNumber int, rule string, messageFormat string, messageArgs ...interface{}) {
+		result = append(result, &lintDiagnostic{
+			Filepath:   diagnosticFilepath,
+			LineNumber: lineNumber,
+			Rule:       rule,
+			Message:    fmt.Sprintf(messageFormat, messageArgs...),
+		})
+	}
+	addDiagnostic := func(lineNumber int, rule string, messageFormat string, messageArgs ...interface{}) {
+		addDiagnosticToFile(filepath, lineNumber, rule, messageFormat, messageArgs...)
+	}
+
+	unreleasedVersions := changelog.GetUnreleasedVersions()
+	if len(unreleasedVersions) == 0 {
+		addDiagnostic(documentLineNumber, singleTBDLintRule, "No TBD header was found")
+	}
+	for idx, version := range unreleasedVersions {
+		if idx > 0 {
+			addDiagnostic(version.LineNumber, singleTBDLintRule, "Found more than one TBD header")
+			continue
+		}
+		if version != changelog.Versions[0] {
+			addDiagnostic(version.LineNumber, singleTBDLintRule, "The TBD header must come before all released versions")
+		}
+	}
+
+	// The archives continue where the changelog leaves off, so released versions are checked as one sequence
+	releasedVersionFilepaths := map[*changelog_ast.Version]string{}
+	releasedVersions := changelog.GetReleasedVersions()
+	for _, version := range releasedVersions {
+		releasedVersionFilepaths[version] = filepath
+	}
+	for _, archived := range archivedChangelogs {
+		for _, version := range archived.changelog.GetReleasedVersions() {
+			releasedVersionFilepaths[version] = archived.filepath
+			releasedVersions = append(releasedVersions, version)
+		}
+	}
+
+	var previousVersion *changelog_ast.Version
+	seenVersions := map[string]*changelog_ast.Version{}
+	for _, version := range releasedVersions {
+		versionFilepath := releasedVersionFilepaths[version]
+		if firstVersion, found := seenVersions[version.Name]; found {
+			addDiagnosticToFile(versionFilepath, version.LineNumber, duplicateVersionLintRule, "Version '%s' was already released at %s:%d", version.Name, releasedVersionFilepaths[firstVersion], firstVersion.LineNumber)
+			continue
+		}
+		seenVersions[version.Name] = version
+
+		if version.Semver == nil {
+			addDiagnosticToFile(versionFilepath, version.LineNumber, versionOrderLintRule, "Version '%s' isn't a valid semantic version", version.Name)
+			continue
+		}
+		if previousVersion != nil {
+			if !previousVersion.Semver.GreaterThan(version.Semver) {
+				addDiagnosticToFile(versionFilepath, version.LineNumber, versionOrderLintRule, "Version '%s' must come before version '%s' because versions must be in descending order", version.Name, previousVersion.Name)
+			} else if !isNextVersion(version, previousVersion) {
+				addDiagnosticToFile(releasedVersionFilepaths[previousVersion], previousVersion.LineNumber, versionGapLintRule, "Version '%s' doesn't directly follow the version below it, '%s'", previousVersion.Name, version.Name)
+			}
+		}
+		previousVersion = version
+	}
+
+	allowedCategoryKeys := map[string]bool{}
+	for _, category := range allowedCategories {
+		allowedCategoryKeys[strings.ToLower(strings.TrimSpace(category))] = true
+	}
+	referenceBullet := ""
+	for _, version := range changelog.Versions {
+		if !version.IsUnreleased() && !hasAnyChanges(version) {
+			addDiagnostic(version.LineNumber, emptyReleasedSectionLintRule, "Released version '%s' has no changes", version.Name)
+		}
+		for _, section := range version.Sections {
+			if !section.IsUntitled() {
+				if !allowedCategoryKeys[section.Key()] && !section.IsBreakingChanges() && !section.IsContributors() {
+					addDiagnostic(section.LineNumber, unknownCategoryLintRule, "Unknown category '%s'; allowed categories are: %s", section.Title, strings.Join(allowedCategories, ", "))
+				}
+				if !version.IsUnreleased() && !section.HasContent() {
+					addDiagnostic(section.LineNumber, emptyReleasedSectionLintRule, "Category '%s' of released version '%s' has no changes", section.Title, version.Name)
+				}
+			}
+			for _, entry := range section.Entries() {
+				if referenceBullet == "" {
+					referenceBullet = entry.Bullet
+					continue
+				}
+				if entry.Bullet != referenceBullet {
+					addDiagnostic(section.GetLineNumber(entry.LineIdx), bulletStyleLintRule, "Entry uses bullet '%s' but the first entry of the changelog uses '%s'", entry.Bullet, referenceBullet)
+				}
+			}
+		}
+	}
+
+	// Problems in the changelog come first, then the ones in each archive in link order
+	filepathOrder := map[string]int{filepath: 0}
+	for idx, archived := range archivedChangelogs {
+		filepathOrder[archived.filepath] = idx + 1
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Filepath != result[j].Filepath {
+			return filepathOrder[result[i].Filepath] < filepathOrder[result[j].Filepath]
+		}
+		return result[i].LineNumber < result[j].LineNumber
+	})
+	return result
+}
+
+// Archive links are relative to the changelog; links to files that can't be read are reported as problems rather than errors
+func readArchivedChangelogs(changelog *changelog_ast.Changelog, changelogFilepath string) ([]*archivedChangelog, []*lintDiagnostic) {
+	var archivedChangelogs []*archivedChangelog
+	var diagnostics []*lintDiagnostic
+	for _, link := range changelog.GetArchiveLinks() {
+		archiveFilepath := path.Join(path.Dir(changelogFilepath), link.Target)
+		archiveFile, err := os.ReadFile(archiveFilepath)
+		if err != nil {
+			diagnostics = append(diagnostics, &lintDiagnostic{
+				Filepath:   changelogFilepath,
+				LineNumber: link.LineNumber,
+				Rule:       archiveLinkLintRule,
+				Message:    fmt.Sprintf("Archive file '%s' couldn't be read: %v", archiveFilepath, err),
+			})
+			continue
+		}
+		archivedChangelogs = append(archivedChangelogs, &archivedChangelog{
+			filepath:  archiveFilepath,
+			changelog: changelog_ast.Parse(archiveFile, changelog.Format),
+		})
+	}
+	return archivedChangelogs, diagnostics
+}
+
+// Unlike the version having content, category subheaders with nothing under them don't count
+func hasAnyChanges(version *changelog_ast.Version) bool {
+	for _, section := range version.Sections {
+		if section.HasContent() {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the newer version is a patch, minor or major bump of the older one
+func isNextVersion(olderVersion *changelog_ast.Version, newerVersion *changelog_ast.Version) bool {
+	nextPatchVersion := olderVersion.Semver.IncPatch()
+	nextMinorVersion := olderVersion.Semver.IncMinor()
+	nextMajorVersion := olderVersion.Semver.IncMajor()
+	return newerVersion.Semver.Equal(&nextPatchVersion) ||
+		newerVersion.Semver.Equal(&nextMinorVersion) ||
+		newerVersion.Semver.Equal(&nextMajorVersion)
+}
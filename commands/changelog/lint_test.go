@@ -0,0 +1,93 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+const testChangelogFilepath = "docs/changelog.md"
+
+func TestLintChangelogAcceptsValidChangelog(t *testing.T) {
+	changelogStr := `# TBD
+### Features
+* Something
+
+# 0.2.0
+### Breaking Changes
+* A break
+
+# 0.1.1
+### Fixes
+* A fix
+
+# 0.1.0
+* Initial release
+`
+	require.Empty(t, lintTestChangelog(changelogStr))
+}
+
+func TestLintChangelogReportsProblems(t *testing.T) {
+	changelogStr := `# 0.1.3
+* Something
+
+# TBD
+### Stuff
+- Something else
+
+# 0.1.3
+* Duplicate
+
+# 0.1.0
+### Fixes
+
+# 0.1.1
+* Out of order
+
+# TBD
+`
+	diagnostics := lintTestChangelog(changelogStr)
+
+	type ruleAndLine struct {
+		rule       string
+		lineNumber int
+	}
+	var actual []ruleAndLine
+	for _, diagnostic := range diagnostics {
+		require.Equal(t, testChangelogFilepath, diagnostic.Filepath)
+		actual = append(actual, ruleAndLine{rule: diagnostic.Rule, lineNumber: diagnostic.LineNumber})
+	}
+	require.Equal(t, []ruleAndLine{
+		{rule: versionGapLintRule, lineNumber: 1},
+		{rule: singleTBDLintRule, lineNumber: 4},
+		{rule: unknownCategoryLintRule, lineNumber: 5},
+		{rule: bulletStyleLintRule, lineNumber: 6},
+		{rule: duplicateVersionLintRule, lineNumber: 8},
+		{rule: emptyReleasedSectionLintRule, lineNumber: 11},
+		{rule: emptyReleasedSectionLintRule, lineNumber: 12},
+		{rule: versionOrderLintRule, lineNumber: 14},
+		{rule: singleTBDLintRule, lineNumber: 17},
+	}, actual)
+}
+
+func TestLintChangelogReportsMissingTBD(t *testing.T) {
+	diagnostics := lintTestChangelog("# 0.1.0\n* Something\n")
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, singleTBDLintRule, diagnostics[0].Rule)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func lintTestChangelog(changelogStr string) []*lintDiagnostic {
+	changelog := changelog_ast.Parse([]byte(changelogStr), changelog_ast.KudetFormat)
+	return lintChangelog(changelog, testChangelogFilepath, changelog_ast.KudetFormat.GetKnownCategories(), nil)
+}
+
+func TestLintChangelogAllowsContributors(t *testing.T) {
+	diagnostics := lintTestChangelog("# TBD\n\n# 0.1.0\n### Fixes\n* A fix\n\n### Contributors\n* Ada Lovelace\n")
+	require.Empty(t, diagnostics)
+}
@@ -0,0 +1,129 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+const (
+	showCmdStr = "show [version]"
+
+	rangeFlagStr        = "range"
+	rangeFlagDefaultVal = ""
+	rangeSeparator      = ".."
+
+	versionPrefixToTrim = "v"
+)
+
+var versionRangeStr string
+var shouldOutputShowJson bool
+
+var showCmd = &cobra.Command{
+	Use:   showCmdStr,
+	Short: "Prints the changelog section of a version, or of a range of versions",
+	Long: `Prints the changelog section of the given version (or TBD for the unreleased changes) as markdown, e.g. to paste into a GitHub release.
+With --range, every section between the two versions (both inclusive) is printed instead, e.g. 'kudet changelog show --range 1.2.0..1.4.0'.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runShow,
+}
+
+func init() {
+	showCmd.Flags().StringVar(&versionRangeStr, rangeFlagStr, rangeFlagDefaultVal, "Inclusive range of versions to print, in the form 'X.Y.Z..X.Y.Z'")
+	showCmd.Flags().BoolVar(&shouldOutputShowJson, jsonFlagStr, jsonFlagDefaultVal, "If set, the versions will be printed as JSON rather than markdown")
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	if (len(args) == 0) == (versionRangeStr == "") {
+		return stacktrace.NewError("Exactly one of a version argument or the '--%s' flag must be provided", rangeFlagStr)
+	}
+
+	changelog, err := readChangelogWithArchives()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the changelog and its archives")
+	}
+
+	var versions []*changelog_ast.Version
+	if versionRangeStr != "" {
+		versions, err = getVersionsInRange(changelog, versionRangeStr)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the versions in range '%s'", versionRangeStr)
+		}
+	} else {
+		versionName := strings.TrimPrefix(args[0], versionPrefixToTrim)
+		version := changelog.GetVersion(versionName)
+		if version == nil {
+			return stacktrace.NewError("No version '%s' was found in changelog '%s'", versionName, changelogFilepath)
+		}
+		versions = []*changelog_ast.Version{version}
+	}
+
+	if shouldOutputShowJson {
+		versionJsons := []*versionJson{}
+		for _, version := range versions {
+			versionJsons = append(versionJsons, newVersionJson(version))
+		}
+		versionsJson, err := json.MarshalIndent(versionJsons, "", "  ")
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred serializing the versions to JSON")
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(versionsJson))
+		return nil
+	}
+
+	var versionMarkdowns []string
+	for _, version := range versions {
+		versionMarkdowns = append(versionMarkdowns, renderVersionMarkdown(version))
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), strings.Join(versionMarkdowns, "\n\n"))
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Returns the released versions between the two ends of the range (both inclusive), in changelog order
+func getVersionsInRange(changelog *changelog_ast.Changelog, rangeStr string) ([]*changelog_ast.Version, error) {
+	lowerBound, upperBound, err := parseVersionRange(rangeStr)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing version range '%s'", rangeStr)
+	}
+
+	var result []*changelog_ast.Version
+	for _, version := range changelog.GetReleasedVersions() {
+		if version.Semver == nil || version.Semver.LessThan(lowerBound) || version.Semver.GreaterThan(upperBound) {
+			continue
+		}
+		result = append(result, version)
+	}
+	if len(result) == 0 {
+		return nil, stacktrace.NewError("No released versions between '%s' and '%s' were found in the changelog", lowerBound.String(), upperBound.String())
+	}
+	return result, nil
+}
+
+// Parses a 'X.Y.Z..X.Y.Z' range, returning its ends in ascending order
+func parseVersionRange(rangeStr string) (*semver.Version, *semver.Version, error) {
+	rangeEnds := strings.Split(rangeStr, rangeSeparator)
+	if len(rangeEnds) != 2 {
+		return nil, nil, stacktrace.NewError("Version range '%s' isn't of the form 'X.Y.Z%sX.Y.Z'", rangeStr, rangeSeparator)
+	}
+	var rangeEndVersions []*semver.Version
+	for _, rangeEnd := range rangeEnds {
+		rangeEndVersion, err := semver.StrictNewVersion(strings.TrimPrefix(strings.TrimSpace(rangeEnd), versionPrefixToTrim))
+		if err != nil {
+			return nil, nil, stacktrace.Propagate(err, "An error occurred parsing '%s' as a semantic version", rangeEnd)
+		}
+		rangeEndVersions = append(rangeEndVersions, rangeEndVersion)
+	}
+	if rangeEndVersions[0].GreaterThan(rangeEndVersions[1]) {
+		return rangeEndVersions[1], rangeEndVersions[0], nil
+	}
+	return rangeEndVersions[0], rangeEndVersions[1], nil
+}
@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+const showTestChangelogStr = `# TBD
+* Something unreleased
+
+# 1.4.0 (2026-10-16)
+### Breaking Changes
+* A break
+  with more details
+
+# 1.3.0
+* Something
+
+# 1.2.0
+### Fixes
+* A fix
+
+# 1.1.0
+* Something old
+`
+
+func TestGetVersionsInRange(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte(showTestChangelogStr), changelog_ast.KudetFormat)
+
+	versions, err := getVersionsInRange(changelog, "1.2.0..v1.4.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.4.0", "1.3.0", "1.2.0"}, getVersionNames(versions))
+
+	versions, err = getVersionsInRange(changelog, "1.3.0..1.1.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.3.0", "1.2.0", "1.1.0"}, getVersionNames(versions))
+
+	_, err = getVersionsInRange(changelog, "2.0.0..3.0.0")
+	require.Error(t, err)
+
+	_, err = getVersionsInRange(changelog, "1.2.0")
+	require.Error(t, err)
+}
+
+func TestRenderVersionMarkdown(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte(showTestChangelogStr), changelog_ast.KudetFormat)
+	require.Equal(t, "# 1.3.0\n* Something", renderVersionMarkdown(changelog.GetVersion("1.3.0")))
+}
+
+func TestNewVersionJson(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte(showTestChangelogStr), changelog_ast.KudetFormat)
+	require.Equal(t, &versionJson{
+		Version:            "1.4.0",
+		Date:               "2026-10-16",
+		IsUnreleased:       false,
+		HasBreakingChanges: true,
+		Categories: []*categoryJson{
+			{
+				Title:              "Breaking Changes",
+				HasBreakingChanges: true,
+				Entries:            []string{"A break\n  with more details"},
+			},
+		},
+	}, newVersionJson(changelog.GetVersion("1.4.0")))
+}
+
+func TestShowFindsArchivedVersions(t *testing.T) {
+	changelog := readArchivedTestChangelog(t, showTestChangelogStr, "1.3.0")
+	archivedVersion := changelog.GetVersion("1.2.0")
+	require.NotNil(t, archivedVersion)
+	require.Equal(t, "# 1.2.0\n\n### Fixes\n* A fix", renderVersionMarkdown(archivedVersion))
+
+	versions, err := getVersionsInRange(changelog, "1.1.0..1.3.0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.3.0", "1.2.0", "1.1.0"}, getVersionNames(versions))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getVersionNames(versions []*changelog_ast.Version) []string {
+	var result []string
+	for _, version := range versions {
+		result = append(result, version.Name)
+	}
+	return result
+}
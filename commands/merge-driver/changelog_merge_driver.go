@@ -0,0 +1,427 @@
+package mergedriver
+
+import (
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	changelogMergeDriverCmdStr = "changelog <ancestor filepath> <current filepath> <other filepath>"
+
+	changelogFormatFlagStr        = "changelog-format"
+	changelogFormatFlagDefaultVal = string(changelog_ast.KudetFormat)
+
+	lineSeparator = "\n"
+
+	oursConflictMarker      = "<<<<<<< ours"
+	separatorConflictMarker = "======="
+	theirsConflictMarker    = ">>>>>>> theirs"
+)
+
+var emptyLineRegex = regexp.MustCompile("^\\s*$")
+
+var changelogFormatStr string
+var changelogMergeDriverCmd = &cobra.Command{
+	Use:   changelogMergeDriverCmdStr,
+	Short: "Merges three versions of a kudet changelog",
+	Long: `Merges three versions of a kudet changelog, intended to be used as a git merge driver.
+Entries added to the same subsection of the TBD section on both sides are union-merged, and only conflicting edits to released sections are reported as conflicts.
+The merge result is written to the current filepath, as git expects. To register the driver in a repo, run:
+
+  git config merge.kudet-changelog.driver "kudet merge-driver changelog %O %A %B"
+
+and add the following line to the repo's .gitattributes file:
+
+  docs/changelog.md merge=kudet-changelog`,
+	Args: cobra.ExactArgs(3),
+	RunE: runChangelogMergeDriver,
+}
+
+func init() {
+	changelogMergeDriverCmd.Flags().StringVar(&changelogFormatStr, changelogFormatFlagStr, changelogFormatFlagDefaultVal, "The format of the changelog being merged ("+strings.Join(changelog_ast.GetAllFormatStrs(), "|")+")")
+}
+
+func runChangelogMergeDriver(cmd *cobra.Command, args []string) error {
+	ancestorFilepath, currentFilepath, otherFilepath := args[0], args[1], args[2]
+
+	changelogFormat, err := changelog_ast.ParseFormat(changelogFormatStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the changelog format")
+	}
+
+	ancestorFile, err := os.ReadFile(ancestorFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the ancestor changelog at '%s'", ancestorFilepath)
+	}
+	currentFileInfo, err := os.Stat(currentFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to retrieve file info for the current changelog at '%s'", currentFilepath)
+	}
+	currentFile, err := os.ReadFile(currentFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the current changelog at '%s'", currentFilepath)
+	}
+	otherFile, err := os.ReadFile(otherFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading the other changelog at '%s'", otherFilepath)
+	}
+
+	mergedLines, numConflicts := mergeChangelogs(
+		changelog_ast.Parse(ancestorFile, changelogFormat),
+		changelog_ast.Parse(currentFile, changelogFormat),
+		changelog_ast.Parse(otherFile, changelogFormat),
+	)
+
+	mergedFile := []byte(strings.Join(mergedLines, lineSeparator))
+	if err := os.WriteFile(currentFilepath, mergedFile, currentFileInfo.Mode()); err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the merged changelog to '%s'", currentFilepath)
+	}
+
+	if numConflicts > 0 {
+		return stacktrace.NewError("Merging the changelog resulted in '%d' conflicts in released sections that need to be resolved manually in '%s'", numConflicts, currentFilepath)
+	}
+	logrus.Debugf("Merged changelog cleanly into '%s'", currentFilepath)
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Returns the merged lines along with the number of conflicts that couldn't be resolved automatically
+func mergeChangelogs(base *changelog_ast.Changelog, ours *changelog_ast.Changelog, theirs *changelog_ast.Changelog) ([]string, int) {
+	numConflicts := 0
+	mergedPreamble, numPreambleConflicts := mergeLines(base.Preamble, ours.Preamble, theirs.Preamble)
+	numConflicts += numPreambleConflicts
+	result := mergedPreamble
+
+	baseVersions := getVersionsByKey(base)
+	oursVersions := getVersionsByKey(ours)
+	theirsVersions := getVersionsByKey(theirs)
+	for _, versionKey := range getMergedVersionKeys(ours, theirs) {
+		baseVersion := baseVersions[versionKey]
+		oursVersion := oursVersions[versionKey]
+		theirsVersion := theirsVersions[versionKey]
+
+		if versionKey == changelog_ast.VersionToBeReleasedPlaceholderStr && oursVersion != nil && theirsVersion != nil {
+			result = append(result, unionMergeVersion(baseVersion, oursVersion, theirsVersion)...)
+			continue
+		}
+
+		mergedVersion, numVersionConflicts := mergeLines(renderVersion(baseVersion), renderVersion(oursVersion), renderVersion(theirsVersion))
+		numConflicts += numVersionConflicts
+		result = append(result, mergedVersion...)
+	}
+
+	mergedArchive, numArchiveConflicts := mergeLines(base.Archive, ours.Archive, theirs.Archive)
+	numConflicts += numArchiveConflicts
+	result = append(result, mergedArchive...)
+
+	mergedFooter, numFooterConflicts := mergeLines(base.Footer, ours.Footer, theirs.Footer)
+	numConflicts += numFooterConflicts
+	return append(result, mergedFooter...), numConflicts
+}
+
+// Line-level three-way merge like git's: lines unchanged on both sides split the lines into hunks that are merged separately,
+// so edits to different lines of a block don't conflict; returns the merged lines and the number of conflicting hunks
+func mergeLines(base []string, ours []string, theirs []string) ([]string, int) {
+	oursMatches := getLongestCommonSubsequenceMatches(base, ours)
+	theirsMatches := getLongestCommonSubsequenceMatches(base, theirs)
+
+	var result []string
+	numConflicts := 0
+	baseIdx, oursIdx, theirsIdx := 0, 0, 0
+	for {
+		// The next base line that's unchanged on both sides ends the hunk
+		hunkEndBaseIdx := baseIdx
+		for hunkEndBaseIdx < len(base) && (oursMatches[hunkEndBaseIdx] < 0 || theirsMatches[hunkEndBaseIdx] < 0) {
+			hunkEndBaseIdx++
+		}
+		hunkEndOursIdx, hunkEndTheirsIdx := len(ours), len(theirs)
+		if hunkEndBaseIdx < len(base) {
+			hunkEndOursIdx, hunkEndTheirsIdx = oursMatches[hunkEndBaseIdx], theirsMatches[hunkEndBaseIdx]
+		}
+
+		mergedHunk, isConflict := mergeBlocks(base[baseIdx:hunkEndBaseIdx], ours[oursIdx:hunkEndOursIdx], theirs[theirsIdx:hunkEndTheirsIdx])
+		if isConflict {
+			numConflicts++
+		}
+		result = append(result, mergedHunk...)
+
+		if hunkEndBaseIdx == len(base) {
+			return result, numConflicts
+		}
+		result = append(result, base[hunkEndBaseIdx])
+		baseIdx, oursIdx, theirsIdx = hunkEndBaseIdx+1, hunkEndOursIdx+1, hunkEndTheirsIdx+1
+	}
+}
+
+// Standard three-way merge of a block of lines that is treated as a single unit
+func mergeBlocks(base []string, ours []string, theirs []string) ([]string, bool) {
+	if areLinesEqual(ours, theirs) || areLinesEqual(base, theirs) {
+		return ours, false
+	}
+	if areLinesEqual(base, ours) {
+		return theirs, false
+	}
+	result := []string{oursConflictMarker}
+	result = append(result, ours...)
+	result = append(result, separatorConflictMarker)
+	result = append(result, theirs...)
+	result = append(result, theirsConflictMarker)
+	return result, true
+}
+
+// For every line of the base, returns the index of the line it's matched with in the other lines, or -1 if it was removed or changed
+func getLongestCommonSubsequenceMatches(base []string, other []string) []int {
+	// lengths[i][j] is the length of the longest common subsequence of base[i:] and other[j:]
+	lengths := make([][]int, len(base)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(other)+1)
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			if base[i] == other[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	result := make([]int, len(base))
+	i, j := 0, 0
+	for i < len(base) {
+		switch {
+		case j < len(other) && base[i] == other[j]:
+			result[i] = j
+			i++
+			j++
+		case j < len(other) && lengths[i][j+1] > lengths[i+1][j]:
+			j++
+		default:
+			result[i] = -1
+			i++
+		}
+	}
+	return result
+}
+
+// Merges the TBD section, where both sides adding entries is the norm rather than a conflict
+func unionMergeVersion(base *changelog_ast.Version, ours *changelog_ast.Version, theirs *changelog_ast.Version) []string {
+	baseSections := getSectionsByKey(base)
+	theirsSections := getSectionsByKey(theirs)
+	oursSections := getSectionsByKey(ours)
+
+	var mergedSections []*changelog_ast.Section
+	for _, oursSection := range ours.Sections {
+		mergedLines := unionMergeLines(
+			getSectionLines(baseSections[oursSection.Key()]),
+			oursSection.Lines,
+			getSectionLines(theirsSections[oursSection.Key()]),
+		)
+		if !oursSection.IsUntitled() && !hasContent(mergedLines) {
+			continue
+		}
+		mergedSections = append(mergedSections, copySectionWithLines(oursSection, mergedLines))
+	}
+
+	// Sections only the other side has get appended, keeping the blank lines that separate this version from the next one at the very end
+	var trailingEmptyLines []string
+	if len(mergedSections) > 0 {
+		lastSection := mergedSections[len(mergedSections)-1]
+		lastSection.Lines, trailingEmptyLines = splitTrailingEmptyLines(lastSection.Lines)
+	}
+	for _, theirsSection := range theirs.Sections {
+		if _, found := oursSections[theirsSection.Key()]; found {
+			continue
+		}
+		mergedLines := unionMergeLines(getSectionLines(baseSections[theirsSection.Key()]), nil, theirsSection.Lines)
+		if !hasContent(mergedLines) {
+			continue
+		}
+		mergedLines, _ = splitTrailingEmptyLines(mergedLines)
+		if len(mergedSections) > 0 {
+			previousSection := mergedSections[len(mergedSections)-1]
+			if previousSection.HasContent() || !previousSection.IsUntitled() {
+				previousSection.Lines = append(previousSection.Lines, "")
+			}
+		}
+		mergedSections = append(mergedSections, copySectionWithLines(theirsSection, mergedLines))
+	}
+	if len(mergedSections) > 0 {
+		lastSection := mergedSections[len(mergedSections)-1]
+		lastSection.Lines = append(lastSection.Lines, trailingEmptyLines...)
+	}
+
+	mergedVersion := *ours
+	mergedVersion.Sections = mergedSections
+	return mergedVersion.Lines()
+}
+
+// Keeps our lines minus the ones the other side removed, followed by the lines only the other side added
+func unionMergeLines(base []string, ours []string, theirs []string) []string {
+	baseLines := getContentLineSet(base)
+	oursLines := getContentLineSet(ours)
+	theirsLines := getContentLineSet(theirs)
+
+	oursContent, oursTrailingEmptyLines := splitTrailingEmptyLines(ours)
+	theirsContent, theirsTrailingEmptyLines := splitTrailingEmptyLines(theirs)
+
+	var result []string
+	for _, line := range oursContent {
+		normalizedLine := strings.TrimRight(line, " \t")
+		if baseLines[normalizedLine] && !theirsLines[normalizedLine] {
+			continue
+		}
+		result = append(result, line)
+	}
+	for _, line := range theirsContent {
+		normalizedLine := strings.TrimRight(line, " \t")
+		if emptyLineRegex.MatchString(line) || baseLines[normalizedLine] || oursLines[normalizedLine] {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	if len(ours) == 0 && hasContent(result) {
+		return append(result, theirsTrailingEmptyLines...)
+	}
+	return append(result, oursTrailingEmptyLines...)
+}
+
+// Versions of both sides in changelog order: TBD first, then released versions in descending order
+func getMergedVersionKeys(ours *changelog_ast.Changelog, theirs *changelog_ast.Changelog) []string {
+	var mergedVersions []*changelog_ast.Version
+	seenKeys := map[string]bool{}
+	for _, version := range ours.Versions {
+		if !seenKeys[version.Name] {
+			mergedVersions = append(mergedVersions, version)
+			seenKeys[version.Name] = true
+		}
+	}
+	for _, version := range theirs.Versions {
+		if seenKeys[version.Name] {
+			continue
+		}
+		seenKeys[version.Name] = true
+		insertionIdx := len(mergedVersions)
+		for idx, mergedVersion := range mergedVersions {
+			if isVersionBefore(version, mergedVersion) {
+				insertionIdx = idx
+				break
+			}
+		}
+		mergedVersions = append(mergedVersions[:insertionIdx], append([]*changelog_ast.Version{version}, mergedVersions[insertionIdx:]...)...)
+	}
+
+	var result []string
+	for _, version := range mergedVersions {
+		result = append(result, version.Name)
+	}
+	return result
+}
+
+func isVersionBefore(version *changelog_ast.Version, otherVersion *changelog_ast.Version) bool {
+	if version.IsUnreleased() {
+		return true
+	}
+	if otherVersion.IsUnreleased() || version.Semver == nil || otherVersion.Semver == nil {
+		return false
+	}
+	return version.Semver.GreaterThan(otherVersion.Semver)
+}
+
+func getVersionsByKey(changelog *changelog_ast.Changelog) map[string]*changelog_ast.Version {
+	result := map[string]*changelog_ast.Version{}
+	for _, version := range changelog.Versions {
+		if _, found := result[version.Name]; !found {
+			result[version.Name] = version
+		}
+	}
+	return result
+}
+
+func getSectionsByKey(version *changelog_ast.Version) map[string]*changelog_ast.Section {
+	result := map[string]*changelog_ast.Section{}
+	if version == nil {
+		return result
+	}
+	for _, section := range version.Sections {
+		if _, found := result[section.Key()]; !found {
+			result[section.Key()] = section
+		}
+	}
+	return result
+}
+
+func getSectionLines(section *changelog_ast.Section) []string {
+	if section == nil {
+		return nil
+	}
+	return section.Lines
+}
+
+func copySectionWithLines(section *changelog_ast.Section, lines []string) *changelog_ast.Section {
+	result := *section
+	result.Lines = lines
+	return &result
+}
+
+func renderVersion(version *changelog_ast.Version) []string {
+	if version == nil {
+		return nil
+	}
+	return version.Lines()
+}
+
+func getContentLineSet(lines []string) map[string]bool {
+	result := map[string]bool{}
+	for _, line := range lines {
+		if emptyLineRegex.MatchString(line) {
+			continue
+		}
+		result[strings.TrimRight(line, " \t")] = true
+	}
+	return result
+}
+
+func splitTrailingEmptyLines(lines []string) ([]string, []string) {
+	contentEndIdx := len(lines)
+	for contentEndIdx > 0 && emptyLineRegex.MatchString(lines[contentEndIdx-1]) {
+		contentEndIdx--
+	}
+	// Copy so appending to one of the halves can never clobber the other
+	content := append([]string{}, lines[:contentEndIdx]...)
+	trailingEmptyLines := append([]string{}, lines[contentEndIdx:]...)
+	return content, trailingEmptyLines
+}
+
+func hasContent(lines []string) bool {
+	for _, line := range lines {
+		if !emptyLineRegex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func areLinesEqual(lines []string, otherLines []string) bool {
+	if len(lines) != len(otherLines) {
+		return false
+	}
+	for idx := range lines {
+		if lines[idx] != otherLines[idx] {
+			return false
+		}
+	}
+	return true
+}
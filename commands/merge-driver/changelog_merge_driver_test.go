@@ -0,0 +1,274 @@
+package mergedriver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeChangelogsOfIdenticalSidesIsLossless(t *testing.T) {
+	changelog := `
+# TBD
+* Something
+### Fixes
+* A fix
+
+# 0.1.1
+### Breaking Changes
+* A break
+`
+	parsed := changelog_ast.Parse([]byte(changelog), changelog_ast.KudetFormat)
+	mergedLines, numConflicts := mergeChangelogs(parsed, parsed, parsed)
+	require.Equal(t, 0, numConflicts)
+	require.Equal(t, changelog, strings.Join(mergedLines, lineSeparator))
+}
+
+func TestMergeChangelogsUnionMergesTBDEntries(t *testing.T) {
+	base := `# TBD
+### Fixes
+* Base fix
+
+# 0.1.0
+* Something
+`
+	ours := `# TBD
+### Fixes
+* Base fix
+* Our fix
+
+# 0.1.0
+* Something
+`
+	theirs := `# TBD
+### Fixes
+* Base fix
+* Their fix
+
+### Features
+* Their feature
+
+# 0.1.0
+* Something
+`
+	expected := `# TBD
+### Fixes
+* Base fix
+* Our fix
+* Their fix
+
+### Features
+* Their feature
+
+# 0.1.0
+* Something
+`
+	requireMergeResult(t, base, ours, theirs, expected, 0)
+}
+
+func TestMergeChangelogsHandlesReleaseOnOtherSide(t *testing.T) {
+	base := `# TBD
+### Fixes
+* Released fix
+
+# 0.1.0
+* Something
+`
+	ours := `# TBD
+### Fixes
+* Released fix
+* New fix
+
+# 0.1.0
+* Something
+`
+	theirs := `# TBD
+
+# 0.1.1
+### Fixes
+* Released fix
+
+# 0.1.0
+* Something
+`
+	expected := `# TBD
+### Fixes
+* New fix
+
+# 0.1.1
+### Fixes
+* Released fix
+
+# 0.1.0
+* Something
+`
+	requireMergeResult(t, base, ours, theirs, expected, 0)
+}
+
+func TestMergeChangelogsReportsConflictingReleasedSectionEdits(t *testing.T) {
+	base := `# TBD
+* Something
+
+# 0.1.0
+* Original
+`
+	ours := `# TBD
+* Something
+
+# 0.1.0
+* Our rewording
+`
+	theirs := `# TBD
+* Something
+
+# 0.1.0
+* Their rewording
+`
+	expected := `# TBD
+* Something
+
+# 0.1.0
+<<<<<<< ours
+* Our rewording
+=======
+* Their rewording
+>>>>>>> theirs
+`
+	requireMergeResult(t, base, ours, theirs, expected, 1)
+}
+
+func TestMergeChangelogsMergesEditsToDifferentLinesOfReleasedVersion(t *testing.T) {
+	base := `# TBD
+* Something
+
+# 1.2.0
+### Features
+* First feature
+* Second feature
+
+### Fixes
+* A fix
+
+# 1.1.0
+* Older
+`
+	ours := `# TBD
+* Something
+
+# 1.2.0
+### Features
+* First feature, reworded by us
+* Second feature
+
+### Fixes
+* A fix
+
+# 1.1.0
+* Older
+`
+	theirs := `# TBD
+* Something
+
+# 1.2.0
+### Features
+* First feature
+* Second feature
+
+### Fixes
+* A fix, reworded by them
+
+# 1.1.0
+* Older
+`
+	expected := `# TBD
+* Something
+
+# 1.2.0
+### Features
+* First feature, reworded by us
+* Second feature
+
+### Fixes
+* A fix, reworded by them
+
+# 1.1.0
+* Older
+`
+	requireMergeResult(t, base, ours, theirs, expected, 0)
+}
+
+func TestMergeChangelogsTakesOneSidedReleasedSectionEdits(t *testing.T) {
+	base := `# TBD
+* Something
+
+# 0.1.0
+* Original
+`
+	theirs := `# TBD
+* Something
+
+# 0.1.0
+* Their rewording
+`
+	requireMergeResult(t, base, base, theirs, theirs, 0)
+}
+
+func TestMergeChangelogsKeepsArchivedVersionsLinks(t *testing.T) {
+	base := `# TBD
+* Something
+
+# 1.0.0
+* First stable
+
+# 0.1.0
+* Initial
+`
+	ours := `# TBD
+* Something
+* Our change
+
+# 1.0.0
+* First stable
+
+# 0.1.0
+* Initial
+`
+	theirs := `# TBD
+* Something
+
+# 1.0.0
+* First stable
+
+# Archived versions
+
+* [0.x](changelog/0.x.md)
+`
+	expected := `# TBD
+* Something
+* Our change
+
+# 1.0.0
+* First stable
+
+# Archived versions
+
+* [0.x](changelog/0.x.md)
+`
+	requireMergeResult(t, base, ours, theirs, expected, 0)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func requireMergeResult(t *testing.T, base string, ours string, theirs string, expected string, expectedNumConflicts int) {
+	mergedLines, numConflicts := mergeChangelogs(
+		changelog_ast.Parse([]byte(base), changelog_ast.KudetFormat),
+		changelog_ast.Parse([]byte(ours), changelog_ast.KudetFormat),
+		changelog_ast.Parse([]byte(theirs), changelog_ast.KudetFormat),
+	)
+	require.Equal(t, expectedNumConflicts, numConflicts)
+	require.Equal(t, expected, strings.Join(mergedLines, lineSeparator))
+}
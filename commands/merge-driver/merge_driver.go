@@ -0,0 +1,19 @@
+package mergedriver
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	mergeDriverCmdStr = "merge-driver"
+)
+
+var MergeDriverCmd = &cobra.Command{
+	Use:   mergeDriverCmdStr,
+	Short: "Custom git merge drivers for files managed by kudet",
+	Long:  "Custom git merge drivers for files managed by kudet. These are meant to be registered in a repo's git config and referenced from its .gitattributes file, rather than being invoked by hand.",
+}
+
+func init() {
+	MergeDriverCmd.AddCommand(changelogMergeDriverCmd)
+}
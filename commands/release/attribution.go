@@ -0,0 +1,168 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/release_tags"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	shortShaLength = 7
+
+	// GitHub's squash merges end the subject with the PR number, e.g. 'Fix the thing (#123)'
+	squashMergePrRegexStr = `\(#([0-9]+)\)\s*$`
+	mergeCommitPrRegexStr = `^Merge pull request #([0-9]+)`
+
+	shaAttributionFormat   = "(%s)"
+	shaPrAttributionFormat = "(%s, #%s)"
+
+	// A PR reference in an entry, e.g. the '(#12)' a squash merged entry often ends with; 'owner/repo#12' is a PR of another repo
+	entryPrReferenceRegexFormat = `(?:^|[^\w/#])#%s\b`
+)
+
+var (
+	squashMergePrRegex = regexp.MustCompile(squashMergePrRegexStr)
+	mergeCommitPrRegex = regexp.MustCompile(mergeCommitPrRegexStr)
+)
+
+// The commit that added a changelog line, along with the PR it was merged in
+type lineAttribution struct {
+	shortSha string
+
+	// Empty if the PR can't be found
+	prNumber string
+}
+
+// Blames the changelog at HEAD, and returns the attribution of every line that was added since the latest release, keyed by 1-indexed line number
+func getChangelogLineAttributions(repo *git.Repository, headHash plumbing.Hash, changelogRelFilepath string) (map[int]*lineAttribution, error) {
+	releaseTags, err := release_tags.GetReleaseTags(repo)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the release tags of the repository")
+	}
+	var latestReleaseTag *release_tags.ReleaseTag
+	if len(releaseTags) > 0 {
+		latestReleaseTag = releaseTags[len(releaseTags)-1]
+	}
+	newCommits, err := release_tags.GetCommitsAfter(repo, latestReleaseTag, headHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commits since the latest release")
+	}
+	newCommitsByHash := map[plumbing.Hash]*object.Commit{}
+	for _, commit := range newCommits {
+		newCommitsByHash[commit.Hash] = commit
+	}
+
+	headCommit, err := repo.CommitObject(headHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting HEAD commit '%s'", headHash)
+	}
+	blameResult, err := git.Blame(headCommit, changelogRelFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred blaming '%s' at HEAD commit '%s'", changelogRelFilepath, headHash)
+	}
+
+	attributionsByHash := map[plumbing.Hash]*lineAttribution{}
+	result := map[int]*lineAttribution{}
+	for lineIdx, line := range blameResult.Lines {
+		lineCommit, found := newCommitsByHash[line.Hash]
+		if !found {
+			continue
+		}
+		attribution, found := attributionsByHash[line.Hash]
+		if !found {
+			prNumber, err := getPrNumber(lineCommit, newCommits)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "An error occurred getting the PR of commit '%s'", line.Hash)
+			}
+			attribution = &lineAttribution{
+				shortSha: line.Hash.String()[:shortShaLength],
+				prNumber: prNumber,
+			}
+			attributionsByHash[line.Hash] = attribution
+		}
+		result[lineIdx+1] = attribution
+	}
+	return result, nil
+}
+
+// Annotates every entry of the released version whose bullet line has an attribution
+// The line numbers are the ones of the changelog as it was parsed, which releasing doesn't change
+func annotateReleasedEntries(releasedVersion *changelog_ast.Version, lineAttributions map[int]*lineAttribution) {
+	for _, section := range releasedVersion.Sections {
+		for _, entry := range section.Entries() {
+			if attribution, found := lineAttributions[section.GetLineNumber(entry.LineIdx)]; found {
+				section.AnnotateEntry(entry, attribution.getAnnotation(section.GetEntryText(entry)))
+			}
+		}
+	}
+}
+
+// The PR is left out of the annotation if the entry already names it, e.g. because it was written like a squash merge subject
+func (attribution *lineAttribution) getAnnotation(entryText string) string {
+	if attribution.prNumber == "" {
+		return fmt.Sprintf(shaAttributionFormat, attribution.shortSha)
+	}
+	entryPrReferenceRegex := regexp.MustCompile(fmt.Sprintf(entryPrReferenceRegexFormat, regexp.QuoteMeta(attribution.prNumber)))
+	if entryPrReferenceRegex.MatchString(entryText) {
+		return fmt.Sprintf(shaAttributionFormat, attribution.shortSha)
+	}
+	return fmt.Sprintf(shaPrAttributionFormat, attribution.shortSha, attribution.prNumber)
+}
+
+// The attributions are keyed by the line numbers of the blamed changelog, so they'd land on the wrong entries if the changelog changed since
+func checkChangelogUnchangedSinceBlame(changelogFilepath string, blamedChangelogFile []byte) error {
+	changelogFile, err := os.ReadFile(changelogFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred reading changelog file '%s'", changelogFilepath)
+	}
+	if !bytes.Equal(changelogFile, blamedChangelogFile) {
+		return stacktrace.NewError("Changelog '%s' was changed after it was blamed, e.g. by a pre-release script, so its entries can't be attributed to the right commits", changelogFilepath)
+	}
+	return nil
+}
+
+// Returns the number of the PR the commit was merged in, or empty if it can't be found among the given commits
+func getPrNumber(commit *object.Commit, candidateMergeCommits []*object.Commit) (string, error) {
+	subject := strings.SplitN(commit.Message, "\n", 2)[0]
+	if matches := squashMergePrRegex.FindStringSubmatch(subject); matches != nil {
+		return matches[1], nil
+	}
+	if matches := mergeCommitPrRegex.FindStringSubmatch(subject); matches != nil {
+		return matches[1], nil
+	}
+
+	// Otherwise the commit came in through a merge commit, which is the one whose merged branch has the commit but whose mainline doesn't
+	for _, mergeCommit := range candidateMergeCommits {
+		matches := mergeCommitPrRegex.FindStringSubmatch(mergeCommit.Message)
+		if matches == nil || mergeCommit.NumParents() < 2 {
+			continue
+		}
+		isInMergedBranch, err := commit.IsAncestor(mergeCommit)
+		if err != nil {
+			return "", stacktrace.Propagate(err, "An error occurred checking whether commit '%s' is an ancestor of merge commit '%s'", commit.Hash, mergeCommit.Hash)
+		}
+		if !isInMergedBranch {
+			continue
+		}
+		mainlineParent, err := mergeCommit.Parent(0)
+		if err != nil {
+			return "", stacktrace.Propagate(err, "An error occurred getting the first parent of merge commit '%s'", mergeCommit.Hash)
+		}
+		isInMainline, err := commit.IsAncestor(mainlineParent)
+		if err != nil {
+			return "", stacktrace.Propagate(err, "An error occurred checking whether commit '%s' is an ancestor of commit '%s'", commit.Hash, mainlineParent.Hash)
+		}
+		if !isInMainline {
+			return matches[1], nil
+		}
+	}
+	return "", nil
+}
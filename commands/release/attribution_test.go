@@ -0,0 +1,99 @@
+package release
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+const attributionTestChangelogRelFilepath = "docs/changelog.md"
+
+// Blame orders history by commit time, so test commits can't all be made in the same second
+var attributionTestCommitTime = time.Now().Add(-time.Hour)
+
+func TestGetChangelogLineAttributions(t *testing.T) {
+	dirpath := t.TempDir()
+	repo, err := git.PlainInit(dirpath, false)
+	require.NoError(t, err)
+
+	releaseHash := commitAttributionTestChangelog(t, repo, dirpath, "# TBD\n\n# 0.1.0\n* Old\n", "Release 0.1.0", nil)
+	_, err = repo.CreateTag("0.1.0", releaseHash, nil)
+	require.NoError(t, err)
+	squashedHash := commitAttributionTestChangelog(t, repo, dirpath, "# TBD\n* Squashed\n\n# 0.1.0\n* Old\n", "Squashed change (#12)", nil)
+	branchHash := commitAttributionTestChangelog(t, repo, dirpath, "# TBD\n* Squashed\n* Merged\n\n# 0.1.0\n* Old\n", "Add merged change", nil)
+	commitAttributionTestChangelog(t, repo, dirpath, "# TBD\n* Squashed\n* Merged\n\n# 0.1.0\n* Old\n", "Merge pull request #34 from owner/branch", []plumbing.Hash{squashedHash, branchHash})
+	directHash := commitAttributionTestChangelog(t, repo, dirpath, "# TBD\n* Squashed\n* Merged\n* Direct\n\n# 0.1.0\n* Old\n", "Direct change", nil)
+
+	lineAttributions, err := getChangelogLineAttributions(repo, directHash, attributionTestChangelogRelFilepath)
+	require.NoError(t, err)
+	require.Equal(t, map[int]*lineAttribution{
+		2: {shortSha: squashedHash.String()[:shortShaLength], prNumber: "12"},
+		3: {shortSha: branchHash.String()[:shortShaLength], prNumber: "34"},
+		4: {shortSha: directHash.String()[:shortShaLength], prNumber: ""},
+	}, lineAttributions)
+
+	changelogFile, err := os.ReadFile(path.Join(dirpath, attributionTestChangelogRelFilepath))
+	require.NoError(t, err)
+	changelog := changelog_ast.Parse(changelogFile, changelog_ast.KudetFormat)
+	require.NoError(t, changelog.ReleaseUnreleasedVersion("0.1.1", ""))
+	annotateReleasedEntries(changelog.GetVersion("0.1.1"), lineAttributions)
+	require.Equal(t, "# TBD\n\n# 0.1.1\n"+
+		"* Squashed ("+squashedHash.String()[:shortShaLength]+", #12)\n"+
+		"* Merged ("+branchHash.String()[:shortShaLength]+", #34)\n"+
+		"* Direct ("+directHash.String()[:shortShaLength]+")\n"+
+		"\n# 0.1.0\n* Old\n", string(changelog.Bytes()))
+}
+
+func TestAnnotateReleasedEntriesSkipsNamedPr(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte("# 1.0.0\n* Squashed (#12)\n* Other repo owner/repo#12\n* Other PR #123\n"), changelog_ast.KudetFormat)
+	lineAttributions := map[int]*lineAttribution{
+		2: {shortSha: "abc1234", prNumber: "12"},
+		3: {shortSha: "abc1234", prNumber: "12"},
+		4: {shortSha: "def5678", prNumber: "12"},
+	}
+
+	annotateReleasedEntries(changelog.GetVersion("1.0.0"), lineAttributions)
+	require.Equal(t, "# 1.0.0\n"+
+		"* Squashed (#12) (abc1234)\n"+
+		"* Other repo owner/repo#12 (abc1234, #12)\n"+
+		"* Other PR #123 (def5678, #12)\n", string(changelog.Bytes()))
+}
+
+func TestCheckChangelogUnchangedSinceBlame(t *testing.T) {
+	changelogFilepath := path.Join(t.TempDir(), "changelog.md")
+	blamedChangelogFile := []byte("# TBD\n* Something\n\n# 0.1.0\n* Old\n")
+	require.NoError(t, os.WriteFile(changelogFilepath, blamedChangelogFile, 0644))
+	require.NoError(t, checkChangelogUnchangedSinceBlame(changelogFilepath, blamedChangelogFile))
+
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte("# TBD\n* Added by a script\n* Something\n\n# 0.1.0\n* Old\n"), 0644))
+	require.ErrorContains(t, checkChangelogUnchangedSinceBlame(changelogFilepath, blamedChangelogFile), "was changed after it was blamed")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func commitAttributionTestChangelog(t *testing.T, repo *git.Repository, dirpath string, changelogContents string, message string, parents []plumbing.Hash) plumbing.Hash {
+	changelogFilepath := path.Join(dirpath, attributionTestChangelogRelFilepath)
+	require.NoError(t, os.MkdirAll(path.Dir(changelogFilepath), 0755))
+	require.NoError(t, os.WriteFile(changelogFilepath, []byte(changelogContents), 0644))
+	attributionTestCommitTime = attributionTestCommitTime.Add(time.Minute)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add(attributionTestChangelogRelFilepath)
+	require.NoError(t, err)
+	commitHash, err := worktree.Commit(message, &git.CommitOptions{
+		Author:  &object.Signature{Name: "Test", Email: "test@kurtosistech.com", When: attributionTestCommitTime},
+		Parents: parents,
+	})
+	require.NoError(t, err)
+	return commitHash
+}
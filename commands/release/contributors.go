@@ -0,0 +1,191 @@
+package release
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/release_tags"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// this is relative to the root of the target repo
+	mailmapRelFilepath      = ".mailmap"
+	mailmapCommentCharacter = "#"
+
+	// A mailmap line is a proper name and/or email, optionally followed by the commit name and/or email they replace
+	mailmapEmailRegexStr = `<([^<>]*)>`
+	mailmapMaxNumEmails  = 2
+)
+
+var (
+	mailmapEmailRegex = regexp.MustCompile(mailmapEmailRegexStr)
+
+	// Matched against both the name and the email of commit authors
+	contributorExcludePatternsFlagDefaultVal = []string{
+		`\[bot\]`,
+		`^dependabot`,
+		`^renovate`,
+		`^github-actions`,
+		`noreply@github\.com$`,
+	}
+)
+
+// An identity as git knows it: a name and an email, either of which can be empty
+type identity struct {
+	name  string
+	email string
+}
+
+type mailmapEntry struct {
+	proper identity
+
+	// An empty commit name matches any name
+	commit identity
+}
+
+// Maps commit authors to their canonical identity, see https://git-scm.com/docs/gitmailmap
+type mailmap struct {
+	entries []*mailmapEntry
+}
+
+// A missing mailmap file is the same as an empty one
+func readMailmap(mailmapFilepath string) (*mailmap, error) {
+	mailmapFile, err := os.ReadFile(mailmapFilepath)
+	if os.IsNotExist(err) {
+		return &mailmap{entries: nil}, nil
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading mailmap file '%s'", mailmapFilepath)
+	}
+	return parseMailmap(string(mailmapFile)), nil
+}
+
+// Lines that aren't valid mailmap entries are ignored, like git does
+func parseMailmap(mailmapContents string) *mailmap {
+	result := &mailmap{entries: nil}
+	for _, line := range strings.Split(mailmapContents, "\n") {
+		if commentIdx := strings.Index(line, mailmapCommentCharacter); commentIdx >= 0 {
+			line = line[:commentIdx]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		emailIdxs := mailmapEmailRegex.FindAllStringSubmatchIndex(line, -1)
+		if len(emailIdxs) == 0 || len(emailIdxs) > mailmapMaxNumEmails || strings.TrimSpace(line[emailIdxs[len(emailIdxs)-1][1]:]) != "" {
+			continue
+		}
+		properName := strings.TrimSpace(line[:emailIdxs[0][0]])
+		properEmail := line[emailIdxs[0][2]:emailIdxs[0][3]]
+		commitName, commitEmail := "", ""
+		if len(emailIdxs) == mailmapMaxNumEmails {
+			commitName = strings.TrimSpace(line[emailIdxs[0][1]:emailIdxs[1][0]])
+			commitEmail = line[emailIdxs[1][2]:emailIdxs[1][3]]
+		}
+		entry := &mailmapEntry{
+			proper: identity{name: properName, email: properEmail},
+			commit: identity{name: commitName, email: commitEmail},
+		}
+		// With a single email, it's both the one to match and, if no proper name is given, the one to use
+		if commitEmail == "" {
+			if properEmail == "" {
+				continue
+			}
+			entry.commit.email = properEmail
+			entry.proper.email = ""
+		}
+		result.entries = append(result.entries, entry)
+	}
+	return result
+}
+
+// The last matching entry wins, and only replaces the parts of the identity that it specifies
+func (mailmap *mailmap) resolve(author identity) identity {
+	result := author
+	for _, entry := range mailmap.entries {
+		if !strings.EqualFold(entry.commit.email, author.email) {
+			continue
+		}
+		if entry.commit.name != "" && !strings.EqualFold(entry.commit.name, author.name) {
+			continue
+		}
+		result = author
+		if entry.proper.name != "" {
+			result.name = entry.proper.name
+		}
+		if entry.proper.email != "" {
+			result.email = entry.proper.email
+		}
+	}
+	return result
+}
+
+// Returns the contributors of the commits on top of the latest release
+func getReleaseContributors(repo *git.Repository, headHash plumbing.Hash, mailmapFilepath string, excludeRegexes []*regexp.Regexp) ([]string, error) {
+	releaseTags, err := release_tags.GetReleaseTags(repo)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the release tags of the repository")
+	}
+	var latestReleaseTag *release_tags.ReleaseTag
+	if len(releaseTags) > 0 {
+		latestReleaseTag = releaseTags[len(releaseTags)-1]
+	}
+	commits, err := release_tags.GetCommitsAfter(repo, latestReleaseTag, headHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the commits since the latest release")
+	}
+	repoMailmap, err := readMailmap(mailmapFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the mailmap")
+	}
+	return getContributors(commits, repoMailmap, excludeRegexes), nil
+}
+
+// Returns the names of the authors of the commits after mailmap resolution, deduplicated by email and sorted case-insensitively
+func getContributors(commits []*object.Commit, mailmap *mailmap, excludeRegexes []*regexp.Regexp) []string {
+	namesByEmail := map[string]string{}
+	for _, commit := range commits {
+		author := mailmap.resolve(identity{name: commit.Author.Name, email: commit.Author.Email})
+		if isExcludedContributor(author, excludeRegexes) {
+			continue
+		}
+		emailKey := strings.ToLower(author.email)
+		if emailKey == "" {
+			emailKey = author.name
+		}
+		if _, found := namesByEmail[emailKey]; !found {
+			namesByEmail[emailKey] = author.name
+		}
+	}
+
+	var result []string
+	seenNames := map[string]bool{}
+	for _, name := range namesByEmail {
+		// The same person can commit from several emails without a mailmap entry tying them together
+		if seenNames[name] {
+			continue
+		}
+		seenNames[name] = true
+		result = append(result, name)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !strings.EqualFold(result[i], result[j]) {
+			return strings.ToLower(result[i]) < strings.ToLower(result[j])
+		}
+		return result[i] < result[j]
+	})
+	return result
+}
+
+func isExcludedContributor(author identity, excludeRegexes []*regexp.Regexp) bool {
+	for _, excludeRegex := range excludeRegexes {
+		if excludeRegex.MatchString(author.name) || excludeRegex.MatchString(author.email) {
+			return true
+		}
+	}
+	return false
+}
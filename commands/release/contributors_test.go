@@ -0,0 +1,57 @@
+package release
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMailmap(t *testing.T) {
+	repoMailmap := parseMailmap(`# Comment
+Ada Lovelace <ada@example.com>
+<grace@example.com> <ghopper@old.example.com>
+Grace Hopper <grace@example.com> <grace.hopper@example.com>
+Alan Turing <alan@example.com> Al <shared@example.com> # trailing comment
+not a valid line <
+`)
+
+	require.Equal(t, identity{name: "Ada Lovelace", email: "ADA@example.com"}, repoMailmap.resolve(identity{name: "ada", email: "ADA@example.com"}))
+	require.Equal(t, identity{name: "ghopper", email: "grace@example.com"}, repoMailmap.resolve(identity{name: "ghopper", email: "ghopper@old.example.com"}))
+	require.Equal(t, identity{name: "Grace Hopper", email: "grace@example.com"}, repoMailmap.resolve(identity{name: "G", email: "grace.hopper@example.com"}))
+	require.Equal(t, identity{name: "Alan Turing", email: "alan@example.com"}, repoMailmap.resolve(identity{name: "Al", email: "shared@example.com"}))
+	require.Equal(t, identity{name: "Someone", email: "shared@example.com"}, repoMailmap.resolve(identity{name: "Someone", email: "shared@example.com"}))
+}
+
+func TestGetContributors(t *testing.T) {
+	repoMailmap := parseMailmap("Grace Hopper <grace@example.com> <grace.hopper@example.com>\n")
+	var excludeRegexes []*regexp.Regexp
+	for _, pattern := range contributorExcludePatternsFlagDefaultVal {
+		excludeRegexes = append(excludeRegexes, regexp.MustCompile(pattern))
+	}
+
+	commits := []*object.Commit{
+		newContributorsTestCommit("grace", "grace.hopper@example.com"),
+		newContributorsTestCommit("Grace Hopper", "grace@example.com"),
+		newContributorsTestCommit("ada lovelace", "ada@example.com"),
+		newContributorsTestCommit("ada lovelace", "ada@work.example.com"),
+		newContributorsTestCommit("Ada Lovelace", "ada@example.com"),
+		newContributorsTestCommit("dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com"),
+		newContributorsTestCommit("github-actions", "actions@github.com"),
+		newContributorsTestCommit("Zed", "zed@example.com"),
+	}
+	require.Equal(t, []string{"ada lovelace", "Grace Hopper", "Zed"}, getContributors(commits, repoMailmap, excludeRegexes))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newContributorsTestCommit(name string, email string) *object.Commit {
+	return &object.Commit{
+		Author: object.Signature{Name: name, Email: email, When: time.Now()},
+	}
+}
@@ -0,0 +1,103 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const (
+	passedGateStatusStr = "passed"
+	failedGateStatusStr = "FAILED"
+)
+
+type gateResult struct {
+	gate     *commandStep
+	duration time.Duration
+
+	// Nil if the gate passed
+	err error
+}
+
+// Runs every gate against the repo in order, and fails if any of them failed or changed any file
+// Every gate runs even if an earlier one failed, so all failures are reported at once
+func runGates(repoDirpath string, worktree *git.Worktree, gates []*commandStep) error {
+	changesBeforeGate, err := getWorktreeChanges(worktree)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the changes in the worktree before running the gates")
+	}
+	var results []*gateResult
+	for _, gate := range gates {
+		startTime := time.Now()
+		gateErr := runScript(context.Background(), gate.Name, gate.getCommandPath(repoDirpath), gate.Args, gate.getWorkingDirpath(repoDirpath), gate.getCommandEnv(nil), gate.Timeout)
+		duration := time.Since(startTime).Round(time.Millisecond)
+
+		changesAfterGate, err := getWorktreeChanges(worktree)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the changes in the worktree after running gate '%s'", gate.Name)
+		}
+		if newChangeDescriptions := getNewChangeDescriptions(changesBeforeGate, changesAfterGate); len(newChangeDescriptions) > 0 {
+			changesErr := stacktrace.NewError("Gates must not change any file, but this one did (see 'git status'):\n%s", strings.Join(newChangeDescriptions, "\n"))
+			if gateErr != nil {
+				changesErr = stacktrace.Propagate(gateErr, "%v\nIt also failed", changesErr)
+			}
+			gateErr = changesErr
+		}
+		// Changes are only blamed on the gate that made them
+		changesBeforeGate = changesAfterGate
+
+		results = append(results, &gateResult{
+			gate:     gate,
+			duration: duration,
+			err:      gateErr,
+		})
+	}
+
+	logrus.Infof("Gate results:")
+	var failedGateNames []string
+	var failureDescriptions []string
+	for _, result := range results {
+		status := passedGateStatusStr
+		if result.err != nil {
+			status = failedGateStatusStr
+			failedGateNames = append(failedGateNames, result.gate.Name)
+			failureDescriptions = append(failureDescriptions, fmt.Sprintf("Gate '%s' failed: %v", result.gate.Name, result.err))
+		}
+		logrus.Infof("  %-6s %s (%s)", status, result.gate.Name, result.duration)
+	}
+	if len(failedGateNames) > 0 {
+		return stacktrace.NewError(
+			"%d of %d gate(s) failed (%s):\n%s",
+			len(failedGateNames),
+			len(results),
+			strings.Join(failedGateNames, ", "),
+			strings.Join(failureDescriptions, "\n"),
+		)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Describes the changes that are new or of a different kind after than before
+func getNewChangeDescriptions(changesBefore []*fileChange, changesAfter []*fileChange) []string {
+	kindsBefore := map[string]fileChangeKind{}
+	for _, change := range changesBefore {
+		kindsBefore[change.filepath] = change.kind
+	}
+	var result []string
+	for _, change := range changesAfter {
+		if kindBefore, found := kindsBefore[change.filepath]; found && kindBefore == change.kind {
+			continue
+		}
+		result = append(result, string(change.kind)+" "+change.filepath)
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package release
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGates(t *testing.T) {
+	dirpath := t.TempDir()
+	repo, err := git.PlainInit(dirpath, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "tracked.txt"), []byte("original\n"), 0644))
+	writePreReleaseTestScript(t, dirpath, "pass.sh", "echo 'all good'\n")
+	writePreReleaseTestScript(t, dirpath, "build.sh", "echo output > build.out\n")
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "echo 'tests failed' >&2\nexit 1\n")
+	writePreReleaseTestScript(t, dirpath, "modify.sh", "echo changed > tracked.txt\ntouch new.txt\n")
+	require.NoError(t, worktree.AddWithOptions(&git.AddOptions{All: true}))
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{Author: newIsolationTestSignature()})
+	require.NoError(t, err)
+	worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern("*.out", nil))
+
+	passingGates := []*commandStep{
+		newTestGate("./pass.sh"),
+		newTestGate("./build.sh"),
+	}
+	require.NoError(t, runGates(dirpath, worktree, passingGates), "Passing gates that only write ignored files should pass")
+
+	err = runGates(dirpath, worktree, append(passingGates, newTestGate("./fail.sh"), newTestGate("./modify.sh"), newTestGate("./pass.sh")))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2 of 5 gate(s) failed (./fail.sh, ./modify.sh)")
+	require.Contains(t, err.Error(), "[./fail.sh stderr] tests failed")
+	require.Contains(t, err.Error(), "added new.txt\nmodified tracked.txt")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newTestGate(command string) *commandStep {
+	return &commandStep{
+		Name: command,
+		scriptCommand: scriptCommand{
+			Command:          command,
+			Args:             nil,
+			WorkingDirectory: "",
+			Env:              nil,
+			Timeout:          0,
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+type hookStage string
+
+const (
+	// After the next version is computed, before the release is confirmed
+	postVersionComputedHookStage hookStage = "post-version-computed"
+	// After the pre-release scripts and changelog update, before the release commit
+	preCommitHookStage hookStage = "pre-commit"
+	// After the release tags are created locally, before anything is pushed
+	postTagHookStage hookStage = "post-tag"
+	// After the release is pushed; the release can't be undone anymore, so failures don't roll anything back
+	postPushHookStage hookStage = "post-push"
+	// After a release failed and was rolled back; failures before the release config is loaded, like fetching or the sync check, can't run hooks
+	onFailureHookStage hookStage = "on-failure"
+)
+
+var allHookStages = []hookStage{postVersionComputedHookStage, preCommitHookStage, postTagHookStage, postPushHookStage, onFailureHookStage}
+
+// What hooks get on their stdin
+type releaseContextJson struct {
+	Hook      string `json:"hook"`
+	RepoRoot  string `json:"repoRoot"`
+	ParentSha string `json:"parentSha"`
+
+	// Only set once the version is computed, which on-failure hooks can run before
+	PreviousVersion    string `json:"previousVersion,omitempty"`
+	Version            string `json:"version,omitempty"`
+	BumpType           string `json:"bumpType,omitempty"`
+	HasBreakingChanges *bool  `json:"breaking,omitempty"`
+	ReleaseNotesFile   string `json:"releaseNotesFile,omitempty"`
+
+	// Only set once the release commit was made
+	ReleaseCommitSha string `json:"releaseCommitSha,omitempty"`
+
+	// Only set once the release tags were created
+	Tags []string `json:"tags,omitempty"`
+
+	// Only set for on-failure hooks
+	Error string `json:"error,omitempty"`
+}
+
+// Runs the hooks of the stage in order, stopping at the first one that fails
+func runHooks(config *releaseConfig, stage hookStage, release *releaseInfo) error {
+	hooks := config.Hooks[stage]
+	if len(hooks) == 0 {
+		return nil
+	}
+	logrus.Infof("Running '%s' hooks...", stage)
+	for _, hook := range hooks {
+		if err := runHook(hook, stage, release, nil); err != nil {
+			return stacktrace.Propagate(err, "The '%s' hook '%s' failed", stage, hook.Name)
+		}
+	}
+	return nil
+}
+
+// Runs every hook of the stage, even if earlier ones failed, and returns an error listing the ones that failed
+// This is for stages where there's nothing left to stop, so every hook should get its chance
+// The release error is passed to the hooks, and can be nil
+func runAllHooks(config *releaseConfig, stage hookStage, release *releaseInfo, releaseErr error) error {
+	hooks := config.Hooks[stage]
+	if len(hooks) == 0 {
+		return nil
+	}
+	logrus.Infof("Running '%s' hooks...", stage)
+	var failureDescriptions []string
+	for _, hook := range hooks {
+		if err := runHook(hook, stage, release, releaseErr); err != nil {
+			failureDescriptions = append(failureDescriptions, fmt.Sprintf("Hook '%s' failed: %v", hook.Name, err))
+		}
+	}
+	if len(failureDescriptions) > 0 {
+		return stacktrace.NewError("%d of %d '%s' hook(s) failed:\n%s", len(failureDescriptions), len(hooks), stage, strings.Join(failureDescriptions, "\n"))
+	}
+	return nil
+}
+
+// Runs the pre-commit hooks, then checks every change the release commit will have, so the files the hooks change must be allowed too
+func runPreCommitHooksAndCheckChanges(config *releaseConfig, release *releaseInfo, worktree *git.Worktree, alwaysAllowedFilepaths []string) error {
+	if err := runHooks(config, preCommitHookStage, release); err != nil {
+		return stacktrace.Propagate(err, "A hook failed before committing the release")
+	}
+
+	logrus.Infof("Checking the changes made for the release...")
+	releaseChanges, err := getWorktreeChanges(worktree)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred getting the changes made for the release")
+	}
+	if err := checkReleaseChanges(releaseChanges, config.AllowedChanges, alwaysAllowedFilepaths); err != nil {
+		return stacktrace.Propagate(err, "Files were changed that can't be part of the release")
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func runHook(hook *commandStep, stage hookStage, release *releaseInfo, releaseErr error) error {
+	releaseContext, err := json.Marshal(newReleaseContextJson(stage, release, releaseErr))
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred serializing the release context for hook '%s'", hook.Name)
+	}
+	return runScriptWithStdin(
+		context.Background(),
+		hook.Name,
+		hook.getCommandPath(release.repoDirpath),
+		hook.Args,
+		hook.getWorkingDirpath(release.repoDirpath),
+		hook.getCommandEnv(release.getEnv()),
+		hook.Timeout,
+		releaseContext,
+	)
+}
+
+func newReleaseContextJson(stage hookStage, release *releaseInfo, releaseErr error) *releaseContextJson {
+	errStr := ""
+	if releaseErr != nil {
+		errStr = releaseErr.Error()
+	}
+	result := &releaseContextJson{
+		Hook:               string(stage),
+		RepoRoot:           release.repoDirpath,
+		ParentSha:          release.parentCommitSha,
+		PreviousVersion:    "",
+		Version:            "",
+		BumpType:           "",
+		HasBreakingChanges: nil,
+		ReleaseNotesFile:   release.releaseNotesFilepath,
+		ReleaseCommitSha:   release.releaseCommitSha,
+		Tags:               release.tags,
+		Error:              errStr,
+	}
+	if release.version != nil {
+		hasBreakingChanges := release.hasBreakingChanges
+		result.PreviousVersion = release.previousVersion.String()
+		result.Version = release.version.String()
+		result.BumpType = string(release.bumpType)
+		result.HasBreakingChanges = &hasBreakingChanges
+	}
+	return result
+}
+
+func isValidHookStage(candidate hookStage) bool {
+	for _, validHookStage := range allHookStages {
+		if candidate == validHookStage {
+			return true
+		}
+	}
+	return false
+}
+
+func getAllHookStagesStr() string {
+	var hookStageStrs []string
+	for _, validHookStage := range allHookStages {
+		hookStageStrs = append(hookStageStrs, string(validHookStage))
+	}
+	return strings.Join(hookStageStrs, ", ")
+}
@@ -0,0 +1,123 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHooksPassesReleaseContextOnStdin(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "hook.sh", "cat > \"$KUDET_REPO_ROOT/context.json\"\n")
+	release := newTestReleaseInfo(dirpath, "1.3.0", minorBumpType)
+	release.tags = []string{"1.3.0", "v1.3.0"}
+	config := newHooksTestConfig(map[hookStage][]*commandStep{postTagHookStage: {newTestGate("./hook.sh")}})
+
+	require.NoError(t, runHooks(config, postTagHookStage, release))
+
+	releaseContextBytes, err := os.ReadFile(path.Join(dirpath, "context.json"))
+	require.NoError(t, err)
+	releaseContext := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(releaseContextBytes, &releaseContext))
+	require.Equal(t, "post-tag", releaseContext["hook"])
+	require.Equal(t, "1.2.2", releaseContext["previousVersion"])
+	require.Equal(t, "1.3.0", releaseContext["version"])
+	require.Equal(t, "minor", releaseContext["bumpType"])
+	require.Equal(t, false, releaseContext["breaking"])
+	require.Equal(t, []interface{}{"1.3.0", "v1.3.0"}, releaseContext["tags"])
+	require.NotContains(t, releaseContext, "releaseCommitSha", "Unknown values should be left out")
+	require.NotContains(t, releaseContext, "error", "Only on-failure hooks should get an error")
+}
+
+func TestRunHooksStopsAtFailure(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "exit 1\n")
+	writePreReleaseTestScript(t, dirpath, "touch.sh", "touch touched.txt\n")
+	config := newHooksTestConfig(map[hookStage][]*commandStep{preCommitHookStage: {newTestGate("./fail.sh"), newTestGate("./touch.sh")}})
+
+	err := runHooks(config, preCommitHookStage, newTestReleaseInfo(dirpath, "1.2.3", patchBumpType))
+	require.ErrorContains(t, err, "The 'pre-commit' hook './fail.sh' failed")
+	require.NoFileExists(t, path.Join(dirpath, "touched.txt"), "Hooks after a failing one shouldn't run")
+
+	require.NoError(t, runHooks(config, postPushHookStage, newTestReleaseInfo(dirpath, "1.2.3", patchBumpType)), "A stage without hooks should pass")
+}
+
+func TestRunAllHooksRunsEveryHook(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "exit 1\n")
+	writePreReleaseTestScript(t, dirpath, "page.sh", "grep -q 'permission denied' && touch paged.txt\n")
+	config := newHooksTestConfig(map[hookStage][]*commandStep{onFailureHookStage: {newTestGate("./fail.sh"), newTestGate("./page.sh")}})
+
+	err := runAllHooks(config, onFailureHookStage, newTestReleaseInfo(dirpath, "1.2.3", patchBumpType), os.ErrPermission)
+	require.ErrorContains(t, err, "1 of 2 'on-failure' hook(s) failed")
+	require.ErrorContains(t, err, "Hook './fail.sh' failed")
+	require.FileExists(t, path.Join(dirpath, "paged.txt"), "Hooks after a failing one should still run, with the release error on their stdin")
+}
+
+func TestRunAllHooksBeforeVersionIsComputed(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "hook.sh", "cat > \"$KUDET_REPO_ROOT/context.json\"\n")
+	release := newTestReleaseInfo(dirpath, "1.2.3", patchBumpType)
+	release.previousVersion = nil
+	release.version = nil
+	release.bumpType = ""
+	release.releaseNotesFilepath = ""
+	config := newHooksTestConfig(map[hookStage][]*commandStep{onFailureHookStage: {newTestGate("./hook.sh")}})
+
+	require.NoError(t, runAllHooks(config, onFailureHookStage, release, os.ErrNotExist))
+
+	releaseContextBytes, err := os.ReadFile(path.Join(dirpath, "context.json"))
+	require.NoError(t, err)
+	releaseContext := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(releaseContextBytes, &releaseContext))
+	require.Equal(t, map[string]interface{}{
+		"hook":      "on-failure",
+		"repoRoot":  dirpath,
+		"parentSha": release.parentCommitSha,
+		"error":     os.ErrNotExist.Error(),
+	}, releaseContext, "Only what's known before the version is computed should be passed")
+}
+
+func TestRunPreCommitHooksAndCheckChangesChecksHookChanges(t *testing.T) {
+	dirpath := t.TempDir()
+	repo, err := git.PlainInit(dirpath, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "package.json"), []byte("{}\n"), 0644))
+	writePreReleaseTestScript(t, dirpath, "bump.sh", "echo '{\"version\": \"1.2.3\"}' > package.json\n")
+	writePreReleaseTestScript(t, dirpath, "leak.sh", "echo secret > credentials.txt\n")
+	require.NoError(t, worktree.AddWithOptions(&git.AddOptions{All: true}))
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{Author: newIsolationTestSignature()})
+	require.NoError(t, err)
+	release := newTestReleaseInfo(dirpath, "1.2.3", patchBumpType)
+
+	config := newHooksTestConfig(map[hookStage][]*commandStep{preCommitHookStage: {newTestGate("./bump.sh")}})
+	config.AllowedChanges = []string{"package.json"}
+	require.NoError(t, runPreCommitHooksAndCheckChanges(config, release, worktree, nil), "Hooks changing allowed files should pass")
+
+	config.Hooks[preCommitHookStage] = append(config.Hooks[preCommitHookStage], newTestGate("./leak.sh"))
+	err = runPreCommitHooksAndCheckChanges(config, release, worktree, nil)
+	require.ErrorContains(t, err, "Files were changed that can't be part of the release")
+	require.ErrorContains(t, err, "added credentials.txt")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newHooksTestConfig(hooks map[hookStage][]*commandStep) *releaseConfig {
+	return &releaseConfig{
+		Gates:              nil,
+		Hooks:              hooks,
+		PreReleaseScripts:  nil,
+		MaxParallelScripts: defaultMaxParallelScripts,
+		AllowedChanges:     nil,
+		VersionFiles:       nil,
+	}
+}
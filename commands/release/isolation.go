@@ -0,0 +1,94 @@
+package release
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	gitCmdStr = "git"
+
+	isolatedWorktreeDirPattern = "kudet-release-*"
+
+	// Isolated worktrees have a detached HEAD, so the release commit is pushed from HEAD rather than from the local main branch
+	isolatedMainBranchPushRefSpecFmt = "HEAD:refs/heads/%s"
+)
+
+// A temporary linked worktree of the repo, checked out at a detached commit, so the release can be made without touching the user's checkout
+type isolatedWorktree struct {
+	// The repo the worktree was added to
+	repoDirpath string
+
+	dirpath    string
+	repository *git.Repository
+
+	isRemoved bool
+}
+
+// Adds a linked worktree of the repo at the given commit in a new temporary directory
+// The caller is responsible for calling remove on the result
+func createIsolatedWorktree(repoDirpath string, commitHash plumbing.Hash) (*isolatedWorktree, error) {
+	dirpath, err := os.MkdirTemp("", isolatedWorktreeDirPattern)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating a temporary directory for the isolated worktree")
+	}
+	// go-git can't add linked worktrees, but it can open them
+	if err := runGitCommand(repoDirpath, "worktree", "add", "--detach", dirpath, commitHash.String()); err != nil {
+		if removeErr := os.RemoveAll(dirpath); removeErr != nil {
+			logrus.Warnf("An error occurred removing temporary directory '%s', which should be removed manually:\n%v", dirpath, removeErr)
+		}
+		return nil, stacktrace.Propagate(err, "An error occurred adding a worktree at commit '%s' in '%s'", commitHash, dirpath)
+	}
+	result := &isolatedWorktree{
+		repoDirpath: repoDirpath,
+		dirpath:     dirpath,
+		repository:  nil,
+		isRemoved:   false,
+	}
+
+	result.repository, err = git.PlainOpenWithOptions(dirpath, &git.PlainOpenOptions{
+		DetectDotGit:          false,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		result.remove()
+		return nil, stacktrace.Propagate(err, "An error occurred opening the isolated worktree in '%s'", dirpath)
+	}
+	return result, nil
+}
+
+// Removes the worktree and its directory, discarding anything that was done in it
+// Failures are only logged, because there's nothing left to undo; removing the worktree again does nothing
+func (worktree *isolatedWorktree) remove() {
+	if worktree.isRemoved {
+		return
+	}
+	worktree.isRemoved = true
+	if err := runGitCommand(worktree.repoDirpath, "worktree", "remove", "--force", worktree.dirpath); err != nil {
+		logrus.Warnf("An error occurred removing isolated worktree '%s'; run 'git worktree remove --force %s' to remove it manually:\n%v", worktree.dirpath, worktree.dirpath, err)
+		return
+	}
+	if err := os.RemoveAll(worktree.dirpath); err != nil {
+		logrus.Warnf("An error occurred removing temporary directory '%s', which should be removed manually:\n%v", worktree.dirpath, err)
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func runGitCommand(workingDirpath string, args ...string) error {
+	gitCmd := exec.Command(gitCmdStr, args...)
+	gitCmd.Dir = workingDirpath
+	output, err := gitCmd.CombinedOutput()
+	if err != nil {
+		return stacktrace.Propagate(err, "Command 'git %s' failed in working directory '%s' with output:\n%s", strings.Join(args, " "), workingDirpath, output)
+	}
+	return nil
+}
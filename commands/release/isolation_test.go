@@ -0,0 +1,86 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsolatedWorktree(t *testing.T) {
+	remoteDirpath := t.TempDir()
+	_, err := git.PlainInit(remoteDirpath, true)
+	require.NoError(t, err)
+
+	repoDirpath := t.TempDir()
+	repo, err := git.PlainInit(repoDirpath, false)
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: originRemoteName, URLs: []string{remoteDirpath}})
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(repoDirpath, "file.txt"), []byte("original\n"), 0644))
+	_, err = worktree.Add("file.txt")
+	require.NoError(t, err)
+	initialCommitHash, err := worktree.Commit("Initial commit", &git.CommitOptions{Author: newIsolationTestSignature()})
+	require.NoError(t, err)
+
+	isolated, err := createIsolatedWorktree(repoDirpath, initialCommitHash)
+	require.NoError(t, err)
+	defer isolated.remove()
+
+	isolatedWorktreeDir, err := isolated.repository.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path.Join(isolated.dirpath, "file.txt"), []byte("released\n"), 0644))
+	require.NoError(t, isolatedWorktreeDir.AddWithOptions(&git.AddOptions{All: true}))
+	releaseCommitHash, err := isolatedWorktreeDir.Commit("Release", &git.CommitOptions{Author: newIsolationTestSignature()})
+	require.NoError(t, err)
+	_, err = isolated.repository.CreateTag("1.0.0", releaseCommitHash, &git.CreateTagOptions{Message: "1.0.0", Tagger: newIsolationTestSignature()})
+	require.NoError(t, err)
+	require.NoError(t, isolated.repository.Push(&git.PushOptions{
+		RemoteName: originRemoteName,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf(isolatedMainBranchPushRefSpecFmt, mainBranchName)),
+			config.RefSpec("refs/tags/1.0.0:refs/tags/1.0.0"),
+		},
+	}))
+
+	// The user's checkout is left alone, but shares the objects and tags made in the isolated worktree
+	fileContents, err := os.ReadFile(path.Join(repoDirpath, "file.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "original\n", string(fileContents))
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, initialCommitHash, head.Hash())
+	_, err = repo.CommitObject(releaseCommitHash)
+	require.NoError(t, err)
+	_, err = repo.Tag("1.0.0")
+	require.NoError(t, err)
+
+	remoteRepo, err := git.PlainOpen(remoteDirpath)
+	require.NoError(t, err)
+	remoteMain, err := remoteRepo.Reference(plumbing.NewBranchReferenceName(mainBranchName), true)
+	require.NoError(t, err)
+	require.Equal(t, releaseCommitHash, remoteMain.Hash())
+
+	isolated.remove()
+	require.NoDirExists(t, isolated.dirpath)
+	worktrees, err := os.ReadDir(path.Join(repoDirpath, gitDirname, "worktrees"))
+	require.True(t, os.IsNotExist(err) || len(worktrees) == 0, "The linked worktree should have been pruned from the repo")
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newIsolationTestSignature() *object.Signature {
+	return &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+}
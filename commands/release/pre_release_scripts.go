@@ -0,0 +1,385 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// How much of a failing script's output is included in the error
+	scriptOutputTailMaxNumLines = 50
+	// Output without newlines is logged in chunks of this size, so a misbehaving script can't use up the memory
+	scriptOutputMaxLineLength = 64 * 1024
+
+	stdoutStreamName = "stdout"
+	stderrStreamName = "stderr"
+
+	// The exit code exec reports for processes that didn't exit normally, e.g. because they were killed by a signal
+	unknownExitCode = -1
+
+	// Means no timeout for scripts
+	noScriptTimeout = time.Duration(0)
+
+	// Passed to scripts whose step has a check, to validate their preconditions without changing anything
+	scriptCheckArg = "--check"
+
+	// A script is executable if any of its owner, group or other execute bits are set
+	executablePermBits = 0111
+)
+
+// Runs the pre-release steps of the config from the root of the repo, starting steps in config order as soon as the steps they need have finished
+// At most the config's max number of parallel scripts run at the same time, and the first failing step stops the ones running alongside it
+func runPreReleaseScripts(repoDirpath string, config *releaseConfig, release *releaseInfo) error {
+	// Skipped steps count as finished, so the steps that need them can still run
+	finishedStepNames := map[string]bool{}
+	// Steps that failed but were allowed to, and the steps skipped because they needed them, which must not run on missing or partial output
+	failedStepNames := map[string]bool{}
+	var pendingSteps []*scriptStep
+	for _, step := range config.PreReleaseScripts {
+		if !step.shouldRun(release) {
+			logrus.Infof("Skipping pre release script '%s' because its conditions don't match this %s release", step.Name, release.bumpType)
+			finishedStepNames[step.Name] = true
+			continue
+		}
+		pendingSteps = append(pendingSteps, step)
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	stepResults := make(chan *scriptStepResult)
+	numRunningSteps := 0
+	var firstErr error
+	for {
+		if firstErr == nil {
+			pendingSteps = skipStepsNeedingFailedSteps(pendingSteps, failedStepNames)
+			var stillPendingSteps []*scriptStep
+			for _, step := range pendingSteps {
+				if numRunningSteps >= config.MaxParallelScripts || !step.areNeedsFinished(finishedStepNames) {
+					stillPendingSteps = append(stillPendingSteps, step)
+					continue
+				}
+				numRunningSteps++
+				go func(step *scriptStep) {
+					stepResults <- &scriptStepResult{
+						step: step,
+						err:  runScriptStep(ctx, repoDirpath, step, release),
+					}
+				}(step)
+			}
+			pendingSteps = stillPendingSteps
+		}
+		if numRunningSteps == 0 {
+			break
+		}
+
+		result := <-stepResults
+		numRunningSteps--
+		finishedStepNames[result.step.Name] = true
+		if result.err == nil || firstErr != nil {
+			continue
+		}
+		if result.step.ContinueOnError {
+			logrus.Warnf("Pre release script '%s' failed, but it's allowed to so the release goes on without it and the scripts that need it:\n%v", result.step.Name, result.err)
+			failedStepNames[result.step.Name] = true
+			continue
+		}
+		firstErr = stacktrace.Propagate(result.err, "Pre release script '%s' failed", result.step.Name)
+		if numRunningSteps > 0 {
+			logrus.Warnf("Stopping the %d other pre release script(s) that are still running because '%s' failed...", numRunningSteps, result.step.Name)
+		}
+		cancelFunc()
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	// Dependencies are validated when the config is loaded, so this only happens if that validation is wrong
+	if len(pendingSteps) > 0 {
+		return stacktrace.NewError("Pre release script '%s' never got to run because the steps it needs never finished", pendingSteps[0].Name)
+	}
+	return nil
+}
+
+// Checks every pre-release step that will run for this release without changing anything, so problems surface before the release is confirmed
+// Every step's command must exist and be executable, and steps with a check must pass it; all problems are reported at once
+func preflightPreReleaseScripts(repoDirpath string, config *releaseConfig, release *releaseInfo) error {
+	var problems []string
+	for _, step := range config.PreReleaseScripts {
+		if !step.shouldRun(release) {
+			continue
+		}
+		if problem := getScriptStepRunnabilityProblem(repoDirpath, step); problem != "" {
+			problems = append(problems, fmt.Sprintf("Pre release script '%s' can't be run: %s", step.Name, problem))
+			continue
+		}
+		if !step.Check {
+			continue
+		}
+		err := runScript(context.Background(), step.Name+" "+scriptCheckArg, step.getCommandPath(repoDirpath), []string{scriptCheckArg}, step.getWorkingDirpath(repoDirpath), step.getEnv(release), step.Timeout)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Pre release script '%s' failed its check: %v", step.Name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return stacktrace.NewError("Found %d problem(s) with the pre release scripts:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+// Returns the pending steps that don't need a failed step, directly or through other steps
+// The skipped steps are added to the failed steps, so the steps that need them are skipped too
+func skipStepsNeedingFailedSteps(pendingSteps []*scriptStep, failedStepNames map[string]bool) []*scriptStep {
+	result := pendingSteps
+	// A step can need a step listed after it, so this goes on until a pass skips nothing
+	for {
+		var stillPendingSteps []*scriptStep
+		for _, step := range result {
+			if failedNeededStepName := step.getFailedNeed(failedStepNames); failedNeededStepName != "" {
+				logrus.Warnf("Skipping pre release script '%s' because the script it needs, '%s', failed or was skipped", step.Name, failedNeededStepName)
+				failedStepNames[step.Name] = true
+				continue
+			}
+			stillPendingSteps = append(stillPendingSteps, step)
+		}
+		if len(stillPendingSteps) == len(result) {
+			return result
+		}
+		result = stillPendingSteps
+	}
+}
+
+type scriptStepResult struct {
+	step *scriptStep
+	err  error
+}
+
+// Runs the step as many times as it's allowed to retry, until it succeeds or the context is cancelled
+func runScriptStep(ctx context.Context, repoDirpath string, step *scriptStep, release *releaseInfo) error {
+	scriptCmdString := step.getCommandPath(repoDirpath)
+	workingDirpath := step.getWorkingDirpath(repoDirpath)
+	extraEnv := step.getEnv(release)
+
+	numAttempts := step.Retries + 1
+	var err error
+	for attempt := 1; attempt <= numAttempts; attempt++ {
+		err = runScript(ctx, step.Name, scriptCmdString, step.getArgs(release), workingDirpath, extraEnv, step.Timeout)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if attempt < numAttempts {
+			logrus.Warnf("Attempt %d of %d of pre release script '%s' failed; retrying...", attempt, numAttempts, step.Name)
+		}
+	}
+	if numAttempts > 1 {
+		return stacktrace.Propagate(err, "All %d attempts of pre release script '%s' failed; the last error was", numAttempts, step.Name)
+	}
+	return err
+}
+
+// Returns why the step's command or working directory can't be used, or an empty string if they can
+func getScriptStepRunnabilityProblem(repoDirpath string, step *scriptStep) string {
+	workingDirpath := step.getWorkingDirpath(repoDirpath)
+	if workingDirInfo, err := os.Stat(workingDirpath); err != nil || !workingDirInfo.IsDir() {
+		return fmt.Sprintf("working directory '%s' doesn't exist or isn't a directory", workingDirpath)
+	}
+
+	scriptCmdString := step.getCommandPath(repoDirpath)
+	if !strings.Contains(scriptCmdString, "/") {
+		if _, err := exec.LookPath(scriptCmdString); err != nil {
+			return fmt.Sprintf("command '%s' wasn't found in the PATH", scriptCmdString)
+		}
+		return ""
+	}
+	scriptInfo, err := os.Stat(scriptCmdString)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("'%s' doesn't exist", scriptCmdString)
+		}
+		return fmt.Sprintf("an error occurred getting info about '%s': %v", scriptCmdString, err)
+	}
+	if !scriptInfo.Mode().IsRegular() {
+		return fmt.Sprintf("'%s' isn't a regular file", scriptCmdString)
+	}
+	if scriptInfo.Mode().Perm()&executablePermBits == 0 {
+		return fmt.Sprintf("'%s' isn't executable; run 'chmod +x %s' and commit the change", scriptCmdString, step.Command)
+	}
+	return ""
+}
+
+// Runs the script, streaming its output through logrus with the script's name as prefix
+// The extra env vars are added to the environment kudet runs in, and a zero timeout means the script can run for as long as it needs
+// The script is killed along with every process it started if the context is cancelled
+// If the script fails, the error has its exit code, duration, working directory and the tail of its output
+func runScript(parentCtx context.Context, scriptName string, scriptCmdString string, args []string, workingDirpath string, extraEnv []string, timeout time.Duration) error {
+	return runScriptWithStdin(parentCtx, scriptName, scriptCmdString, args, workingDirpath, extraEnv, timeout, nil)
+}
+
+// Like runScript, but the script reads the given bytes from its stdin
+func runScriptWithStdin(parentCtx context.Context, scriptName string, scriptCmdString string, args []string, workingDirpath string, extraEnv []string, timeout time.Duration, stdin []byte) error {
+	tail := newOutputTail(scriptOutputTailMaxNumLines)
+	stdoutLogger := newScriptOutputLogger(scriptName, stdoutStreamName, tail)
+	stderrLogger := newScriptOutputLogger(scriptName, stderrStreamName, tail)
+
+	ctx := parentCtx
+	if timeout != noScriptTimeout {
+		var cancelFunc context.CancelFunc
+		ctx, cancelFunc = context.WithTimeout(ctx, timeout)
+		defer cancelFunc()
+	}
+	scriptCmd := exec.Command(scriptCmdString, args...)
+	scriptCmd.Dir = workingDirpath
+	scriptCmd.Env = append(os.Environ(), extraEnv...)
+	scriptCmd.Stdout = stdoutLogger
+	scriptCmd.Stderr = stderrLogger
+	if stdin != nil {
+		scriptCmd.Stdin = bytes.NewReader(stdin)
+	}
+	// The script gets its own process group, so it can be killed together with the processes it started
+	scriptCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	commandDescription := strings.Join(append([]string{scriptCmdString}, args...), " ")
+	logrus.Infof("Running '%s' in working directory '%s'...", commandDescription, workingDirpath)
+	startTime := time.Now()
+	if err := scriptCmd.Start(); err != nil {
+		return stacktrace.Propagate(err, "Command '%s' couldn't be run in working directory '%s'", commandDescription, workingDirpath)
+	}
+	scriptDone := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// Killing only the script would leave processes it started running, and keeping its output open so waiting for it never ends
+			// The processes may have exited on their own in the meantime
+			if err := syscall.Kill(-scriptCmd.Process.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+				logrus.Warnf("An error occurred killing the processes of '%s':\n%v", commandDescription, err)
+			}
+		case <-scriptDone:
+		}
+	}()
+	err := scriptCmd.Wait()
+	close(scriptDone)
+	duration := time.Since(startTime).Round(time.Millisecond)
+	stdoutLogger.flush()
+	stderrLogger.flush()
+
+	if err == nil {
+		logrus.Infof("'%s' finished successfully in %s", commandDescription, duration)
+		return nil
+	}
+	castedErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return stacktrace.Propagate(err, "Command '%s' couldn't be run in working directory '%s'", commandDescription, workingDirpath)
+	}
+	exitDescription := fmt.Sprintf("exit code %d", castedErr.ExitCode())
+	if parentCtx.Err() == context.Canceled {
+		exitDescription = "being stopped"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		exitDescription = fmt.Sprintf("a timeout of %s", timeout)
+	} else if castedErr.ExitCode() == unknownExitCode {
+		exitDescription = castedErr.String()
+	}
+	return stacktrace.NewError(
+		"Command '%s' failed with %s after %s in working directory '%s'; the tail of its output (at most %d lines) was:\n%s",
+		commandDescription,
+		exitDescription,
+		duration,
+		workingDirpath,
+		scriptOutputTailMaxNumLines,
+		tail.String(),
+	)
+}
+
+// Keeps the last lines written to it by any number of output loggers, in the order they were written
+type outputTail struct {
+	mutex       sync.Mutex
+	maxNumLines int
+	lines       []string
+}
+
+func newOutputTail(maxNumLines int) *outputTail {
+	return &outputTail{
+		mutex:       sync.Mutex{},
+		maxNumLines: maxNumLines,
+		lines:       nil,
+	}
+}
+
+func (tail *outputTail) addLine(line string) {
+	tail.mutex.Lock()
+	defer tail.mutex.Unlock()
+	tail.lines = append(tail.lines, line)
+	if len(tail.lines) > tail.maxNumLines {
+		tail.lines = tail.lines[len(tail.lines)-tail.maxNumLines:]
+	}
+}
+
+func (tail *outputTail) String() string {
+	tail.mutex.Lock()
+	defer tail.mutex.Unlock()
+	return strings.Join(tail.lines, "\n")
+}
+
+// An io.Writer that logs every line written to it, and adds them to a tail
+type scriptOutputLogger struct {
+	prefix        string
+	isStderr      bool
+	tail          *outputTail
+	unloggedBytes []byte
+}
+
+func newScriptOutputLogger(scriptName string, streamName string, tail *outputTail) *scriptOutputLogger {
+	return &scriptOutputLogger{
+		prefix:        fmt.Sprintf("[%s %s] ", scriptName, streamName),
+		isStderr:      streamName == stderrStreamName,
+		tail:          tail,
+		unloggedBytes: nil,
+	}
+}
+
+// exec writes each stream from a single goroutine, so a logger is never written to concurrently
+func (logger *scriptOutputLogger) Write(data []byte) (int, error) {
+	logger.unloggedBytes = append(logger.unloggedBytes, data...)
+	for {
+		newlineIdx := bytes.IndexByte(logger.unloggedBytes, '\n')
+		if newlineIdx < 0 {
+			break
+		}
+		logger.logLine(string(logger.unloggedBytes[:newlineIdx]))
+		logger.unloggedBytes = logger.unloggedBytes[newlineIdx+1:]
+	}
+	for len(logger.unloggedBytes) >= scriptOutputMaxLineLength {
+		logger.logLine(string(logger.unloggedBytes[:scriptOutputMaxLineLength]))
+		logger.unloggedBytes = logger.unloggedBytes[scriptOutputMaxLineLength:]
+	}
+	return len(data), nil
+}
+
+// Logs whatever was written after the last newline
+func (logger *scriptOutputLogger) flush() {
+	if len(logger.unloggedBytes) > 0 {
+		logger.logLine(string(logger.unloggedBytes))
+		logger.unloggedBytes = nil
+	}
+}
+
+func (logger *scriptOutputLogger) logLine(line string) {
+	line = strings.TrimRight(line, "\r")
+	logger.tail.addLine(logger.prefix + line)
+	if logger.isStderr {
+		logrus.Warn(logger.prefix + line)
+	} else {
+		logrus.Info(logger.prefix + line)
+	}
+}
@@ -0,0 +1,320 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPreReleaseScripts(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "succeed.sh", "echo \"releasing $1 from $(pwd)\"\necho 'a warning' >&2\nprintf 'no trailing newline'\n")
+	require.NoError(t, os.WriteFile(path.Join(dirpath, preReleaseScriptsFilename), []byte("succeed.sh\n\n"), 0644))
+
+	logHook := logrustest.NewGlobal()
+	defer logHook.Reset()
+	require.NoError(t, runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType))
+
+	var scriptLogs []string
+	for _, entry := range logHook.AllEntries() {
+		if strings.HasPrefix(entry.Message, "[succeed.sh ") {
+			scriptLogs = append(scriptLogs, fmt.Sprintf("%s %s", entry.Level, entry.Message))
+		}
+	}
+	require.Contains(t, scriptLogs, fmt.Sprintf("info [succeed.sh stdout] releasing 1.2.3 from %s", dirpath))
+	require.Contains(t, scriptLogs, "warning [succeed.sh stderr] a warning")
+	require.Contains(t, scriptLogs, "info [succeed.sh stdout] no trailing newline")
+}
+
+func TestRunPreReleaseScriptsReportsFailures(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "echo 'it broke' >&2\nexit 3\n")
+	require.NoError(t, os.WriteFile(path.Join(dirpath, preReleaseScriptsFilename), []byte("fail.sh\n"), 0644))
+
+	err := runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exit code 3")
+	require.Contains(t, err.Error(), fmt.Sprintf("in working directory '%s'", dirpath))
+	require.Contains(t, err.Error(), "[fail.sh stderr] it broke")
+}
+
+func TestRunPreReleaseScriptsKeepsBoundedTail(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "for i in $(seq 1 60); do echo \"line $i\"; done\nexit 1\n")
+	require.NoError(t, os.WriteFile(path.Join(dirpath, preReleaseScriptsFilename), []byte("fail.sh\n"), 0644))
+
+	err := runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "[fail.sh stdout] line 11\n")
+	require.Contains(t, err.Error(), "[fail.sh stdout] line 60")
+	require.NotContains(t, err.Error(), "[fail.sh stdout] line 10\n")
+}
+
+func TestRunPreReleaseScriptsReportsMissingScripts(t *testing.T) {
+	dirpath := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dirpath, preReleaseScriptsFilename), []byte("missing.sh\n"), 0644))
+
+	err := runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing.sh")
+}
+
+func TestRunPreReleaseScriptsFromReleaseConfig(t *testing.T) {
+	dirpath := t.TempDir()
+	require.NoError(t, os.Mkdir(path.Join(dirpath, "scripts"), 0755))
+	writePreReleaseTestScript(t, path.Join(dirpath, "scripts"), "record.sh", "echo \"$STEP $* in $(basename \"$(pwd)\")\" >> ../record.txt\n")
+	writePreReleaseTestScript(t, dirpath, "flaky.sh", "echo attempt >> attempts.txt\n[ \"$(wc -l < attempts.txt)\" -ge 2 ]\n")
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "exit 1\n")
+	releaseConfig := `pre-release-scripts:
+  - name: default args
+    command: scripts/record.sh
+    working-directory: scripts
+    env:
+      STEP: first
+  - name: custom args
+    command: scripts/record.sh
+    args: ["--version", "v{version}"]
+    working-directory: scripts
+    env:
+      STEP: second
+  - name: major only
+    command: scripts/record.sh
+    working-directory: scripts
+    when:
+      bump: [major]
+  - name: flaky
+    command: ./flaky.sh
+    retries: 1
+  - name: allowed to fail
+    command: ./fail.sh
+    continue-on-error: true
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+
+	require.NoError(t, runPreReleaseScriptsForTest(t, dirpath, "1.2.3", minorBumpType))
+
+	record, err := os.ReadFile(path.Join(dirpath, "record.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "first 1.2.3 in scripts\nsecond --version v1.2.3 in scripts\n", string(record))
+	attempts, err := os.ReadFile(path.Join(dirpath, "attempts.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "attempt\nattempt\n", string(attempts))
+}
+
+func TestRunPreReleaseScriptsExportsReleaseEnv(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "env.sh", "echo \"$KUDET_PREVIOUS_VERSION $KUDET_VERSION $KUDET_VERSION_MINOR $KUDET_BUMP_TYPE $KUDET_HAS_BREAKING_CHANGES $KUDET_PARENT_SHA $FOO\" > env.txt\n[ \"$KUDET_REPO_ROOT\" = \"$(pwd)\" ]\n")
+	releaseConfig := `pre-release-scripts:
+  - command: ./env.sh
+    env:
+      FOO: bar
+      KUDET_BUMP_TYPE: overridden
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+
+	require.NoError(t, runPreReleaseScriptsForTest(t, dirpath, "1.3.0", minorBumpType))
+
+	env, err := os.ReadFile(path.Join(dirpath, "env.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "1.2.2 1.3.0 3 overridden false 0123456789abcdef0123456789abcdef01234567 bar\n", string(env))
+}
+
+func TestRunPreReleaseScriptsStopsAtFailures(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "echo attempt >> attempts.txt\nexit 1\n")
+	writePreReleaseTestScript(t, dirpath, "never.sh", "touch ran.txt\n")
+	releaseConfig := `pre-release-scripts:
+  - command: ./fail.sh
+    retries: 2
+  - command: ./never.sh
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+
+	err := runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "All 3 attempts of pre release script './fail.sh' failed")
+	attempts, err := os.ReadFile(path.Join(dirpath, "attempts.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "attempt\nattempt\nattempt\n", string(attempts))
+	require.NoFileExists(t, path.Join(dirpath, "ran.txt"))
+}
+
+func TestRunPreReleaseScriptsInParallel(t *testing.T) {
+	dirpath := t.TempDir()
+	// Each of these only finishes once the other one has started, so they can only succeed by running at the same time
+	writePreReleaseTestScript(t, dirpath, "wait-for.sh", "touch \"started-$1\"\nfor i in $(seq 1 50); do [ -f \"started-$2\" ] && exit 0; sleep 0.1; done\nexit 1\n")
+	writePreReleaseTestScript(t, dirpath, "after.sh", "[ -f started-go ] && [ -f started-python ] && touch after-ran\n")
+	releaseConfig := `max-parallel-scripts: 2
+pre-release-scripts:
+  - name: go sdk
+    command: ./wait-for.sh
+    args: [go, python]
+    outputs: [started-go]
+  - name: python sdk
+    command: ./wait-for.sh
+    args: [python, go]
+    outputs: [started-python]
+  - name: after sdks
+    command: ./after.sh
+    needs: [go sdk, python sdk]
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+
+	require.NoError(t, runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType))
+	require.FileExists(t, path.Join(dirpath, "after-ran"))
+}
+
+func TestRunPreReleaseScriptsStopsSiblingsOnFailure(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "slow.sh", "sleep 10\ntouch slow-finished\n")
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "sleep 0.2\nexit 1\n")
+	writePreReleaseTestScript(t, dirpath, "after.sh", "touch after-ran\n")
+	releaseConfig := `max-parallel-scripts: 3
+pre-release-scripts:
+  - command: ./slow.sh
+    retries: 3
+    outputs: [slow-finished]
+  - command: ./fail.sh
+    outputs: []
+  - command: ./after.sh
+    needs: [./fail.sh]
+    outputs: [after-ran]
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+
+	startTime := time.Now()
+	err := runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Pre release script './fail.sh' failed")
+	require.Less(t, time.Since(startTime), 5*time.Second, "The slow script should have been stopped, rather than retried or waited for")
+	require.NoFileExists(t, path.Join(dirpath, "slow-finished"))
+	require.NoFileExists(t, path.Join(dirpath, "after-ran"))
+}
+
+func TestRunPreReleaseScriptsSkipsStepsNeedingFailedSteps(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "fail.sh", "exit 1\n")
+	writePreReleaseTestScript(t, dirpath, "touch.sh", "touch \"$1-ran\"\n")
+	releaseConfig := `max-parallel-scripts: 2
+pre-release-scripts:
+  - name: indirect
+    command: ./touch.sh
+    args: [indirect]
+    needs: [direct]
+    outputs: [indirect-ran]
+  - name: optional
+    command: ./fail.sh
+    continue-on-error: true
+    outputs: []
+  - name: direct
+    command: ./touch.sh
+    args: [direct]
+    needs: [optional]
+    outputs: [direct-ran]
+  - name: independent
+    command: ./touch.sh
+    args: [independent]
+    outputs: [independent-ran]
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+
+	require.NoError(t, runPreReleaseScriptsForTest(t, dirpath, "1.2.3", patchBumpType), "A failure that's allowed shouldn't stop the release")
+	require.FileExists(t, path.Join(dirpath, "independent-ran"))
+	require.NoFileExists(t, path.Join(dirpath, "direct-ran"), "A script needing a failed script shouldn't run on its missing output")
+	require.NoFileExists(t, path.Join(dirpath, "indirect-ran"), "A script needing a skipped script shouldn't run either")
+}
+
+func TestPreflightPreReleaseScriptsReportsAllProblems(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "ok.sh", "[ \"$1\" = --check ] && touch checked.txt\n")
+	writePreReleaseTestScript(t, dirpath, "bad-check.sh", "[ \"$1\" != --check ]\n")
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "not-executable.sh"), []byte("#!/bin/sh\n"), 0644))
+	releaseConfig := `pre-release-scripts:
+  - command: ./ok.sh
+    check: true
+  - command: ./missing.sh
+  - command: ./not-executable.sh
+  - command: kudet-test-command-that-does-not-exist
+  - command: ./ok.sh
+    working-directory: missing-dir
+  - command: ./bad-check.sh
+    check: true
+  - command: ./missing-but-major-only.sh
+    when:
+      bump: [major]
+`
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte(releaseConfig), 0644))
+	loadedReleaseConfig, err := loadReleaseConfig(dirpath)
+	require.NoError(t, err)
+
+	err = preflightPreReleaseScripts(dirpath, loadedReleaseConfig, newTestReleaseInfo(dirpath, "1.2.3", patchBumpType))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Found 5 problem(s)")
+	require.Contains(t, err.Error(), "'./missing.sh' can't be run")
+	require.Contains(t, err.Error(), "isn't executable; run 'chmod +x ./not-executable.sh'")
+	require.Contains(t, err.Error(), "'kudet-test-command-that-does-not-exist' wasn't found in the PATH")
+	require.Contains(t, err.Error(), "missing-dir' doesn't exist or isn't a directory")
+	require.Contains(t, err.Error(), "'./bad-check.sh' failed its check")
+	require.NotContains(t, err.Error(), "missing-but-major-only.sh")
+	require.FileExists(t, path.Join(dirpath, "checked.txt"))
+}
+
+func TestRunScriptTimesOut(t *testing.T) {
+	dirpath := t.TempDir()
+	writePreReleaseTestScript(t, dirpath, "slow.sh", "exec sleep 10\n")
+
+	err := runScript(context.Background(), "slow.sh", path.Join(dirpath, "slow.sh"), nil, dirpath, nil, 100*time.Millisecond)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed with a timeout of 100ms")
+}
+
+func TestScriptOutputLoggerSplitsLongLines(t *testing.T) {
+	logrus.SetLevel(logrus.WarnLevel)
+	defer logrus.SetLevel(logrus.InfoLevel)
+
+	tail := newOutputTail(2)
+	logger := newScriptOutputLogger("script", stdoutStreamName, tail)
+	_, err := logger.Write([]byte(strings.Repeat("a", scriptOutputMaxLineLength+1)))
+	require.NoError(t, err)
+	logger.flush()
+	require.Equal(t, "[script stdout] "+strings.Repeat("a", scriptOutputMaxLineLength)+"\n[script stdout] a", tail.String())
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func runPreReleaseScriptsForTest(t *testing.T, dirpath string, version string, releaseBumpType bumpType) error {
+	releaseConfig, err := loadReleaseConfig(dirpath)
+	require.NoError(t, err)
+	return runPreReleaseScripts(dirpath, releaseConfig, newTestReleaseInfo(dirpath, version, releaseBumpType))
+}
+
+// The previous version is always 1.2.2
+func newTestReleaseInfo(dirpath string, version string, releaseBumpType bumpType) *releaseInfo {
+	return &releaseInfo{
+		previousVersion:      semver.MustParse("1.2.2"),
+		version:              semver.MustParse(version),
+		bumpType:             releaseBumpType,
+		hasBreakingChanges:   false,
+		repoDirpath:          dirpath,
+		parentCommitSha:      "0123456789abcdef0123456789abcdef01234567",
+		releaseNotesFilepath: path.Join(dirpath, "notes.md"),
+		releaseCommitSha:     "",
+		tags:                 nil,
+	}
+}
+
+func writePreReleaseTestScript(t *testing.T, dirpath string, filename string, body string) {
+	require.NoError(t, os.WriteFile(path.Join(dirpath, filename), []byte("#!/bin/sh\n"+body), 0755))
+}
@@ -2,7 +2,6 @@ package release
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
@@ -11,14 +10,15 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/release_tags"
 	"github.com/kurtosis-tech/stacktrace"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"net/url"
 	"os"
-	"os/exec"
 	"path"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,10 +29,7 @@ const (
 	originRemoteName = "origin"
 	mainBranchName   = "main"
 
-	preReleaseScriptsFilename = ".pre-release-scripts.txt"
-
-	tagsPrefix = "refs/tags/"
-	headRef    = "refs/heads/"
+	headRef = "refs/heads/"
 
 	// The name of the file inside the Git directory which will store when we last fetched (in Unix seconds)
 	lastFetchedFilename               = "last-fetch.txt"
@@ -49,28 +46,45 @@ const (
 	gitIgnoreRelFilepath      = ".gitignore"
 	gitIgnoreCommentCharacter = "#"
 
-	expectedNumTBDHeaderLines         = 1
-	versionToBeReleasedPlaceholderStr = "TBD"
-	sectionHeaderPrefix               = "#"
-	noPreviousVersion                 = "0.0.0"
-	semverRegexStr                    = "^[0-9]+.[0-9]+.[0-9]+$"
+	expectedNumTBDHeaderLines = 1
+	noPreviousVersion         = "0.0.0"
 
 	releaseCmdStr           = "release"
 	bumpMajorFlagDefaultVal = false
 	bumpMajorFlagShortStr   = ""
+
+	changelogFormatFlagStr        = "changelog-format"
+	changelogFormatFlagDefaultVal = string(changelog_ast.KudetFormat)
+
+	stampReleaseDateFlagStr        = "stamp-release-date"
+	stampReleaseDateFlagDefaultVal = false
+	releaseDateLayoutFlagStr       = "release-date-layout"
+	// Go reference time layout, see https://pkg.go.dev/time#pkg-constants
+	releaseDateLayoutFlagDefaultVal   = "2006-01-02"
+	releaseDateTimezoneFlagStr        = "release-date-timezone"
+	releaseDateTimezoneFlagDefaultVal = "Local"
+
+	linkReferencesFlagStr        = "link-references"
+	linkReferencesFlagDefaultVal = false
+
+	listContributorsFlagStr          = "list-contributors"
+	listContributorsFlagDefaultVal   = false
+	contributorExcludePatternFlagStr = "contributor-exclude-pattern"
+
+	annotateEntriesFlagStr        = "annotate-entries"
+	annotateEntriesFlagDefaultVal = false
+
+	isolateFlagStr        = "isolate"
+	isolateFlagDefaultVal = false
+
+	gitSuffix          = ".git"
+	httpsUrlPrefix     = "https://"
+	scpLikeUrlRegexStr = "^(?:[^@/]+@)?([^:/]+):(.+)$"
 )
 
 var (
-	versionToBeReleasedPlaceholderHeaderStr      = fmt.Sprintf("%s %s", sectionHeaderPrefix, versionToBeReleasedPlaceholderStr)
-	versionToBeReleasedPlaceholderHeaderRegexStr = fmt.Sprintf("^%s\\s*%s\\s*$", sectionHeaderPrefix, versionToBeReleasedPlaceholderStr)
-	versionHeaderRegexStr                        = fmt.Sprintf("^%s\\s*[0-9]+.[0-9]+.[0-9]+\\s*$", sectionHeaderPrefix)
-	breakingChangesSubheaderRegexStr             = fmt.Sprintf("^%s%s%s*\\s*[Bb]reak.*$", sectionHeaderPrefix, sectionHeaderPrefix, sectionHeaderPrefix)
-	semverRegex                                  = regexp.MustCompile(semverRegexStr)
-	versionToBeReleasedPlaceholderHeaderRegex    = regexp.MustCompile(versionToBeReleasedPlaceholderHeaderRegexStr)
-	versionHeaderRegex                           = regexp.MustCompile(versionHeaderRegexStr)
-	breakingChangesRegex                         = regexp.MustCompile(breakingChangesSubheaderRegexStr)
-	emptyLineRegex                               = regexp.MustCompile("^\\s*$")
-	shouldWarnAboutUndoingRemotePushMessage      = `ACTION REQUIRED: An error occurred meaning we need to undo our push to '%s', but this is a dangerous operation for its risk that it will destroy history on the remote so you'll need to do this manually.
+	scpLikeUrlRegex                         = regexp.MustCompile(scpLikeUrlRegexStr)
+	shouldWarnAboutUndoingRemotePushMessage = `ACTION REQUIRED: An error occurred meaning we need to undo our push to '%s', but this is a dangerous operation for its risk that it will destroy history on the remote so you'll need to do this manually.
 	Follow these instructions to properly undo this push:
 	1. Run a git fetch to pull down the latest changes from origin main
 	2. Verify that the origin main hasn't had any new commits that would get blown away if we reverted it
@@ -80,6 +94,15 @@ var (
 )
 
 var shouldBumpMajorVersion bool
+var changelogFormatStr string
+var shouldStampReleaseDate bool
+var releaseDateLayout string
+var releaseDateTimezoneStr string
+var shouldLinkReferences bool
+var shouldListContributors bool
+var contributorExcludePatterns []string
+var shouldAnnotateEntries bool
+var shouldIsolate bool
 var ReleaseCmd = &cobra.Command{
 	Use:   releaseCmdStr,
 	Short: "Cuts a new release on the repo",
@@ -90,74 +113,70 @@ var ReleaseCmd = &cobra.Command{
 
 var emptyDomain []string = nil
 
-func parseChangeLogFile(changelogFile []byte) (bool, error) {
-	tbdHeaderFound := false
-	isBreakingChange := false
-
-	foundLastReleasedVersionHeader := false
-	foundNonEmptyLineBeforeLastVersionHeader := false
-	scanner := bufio.NewScanner(bytes.NewReader(changelogFile))
-
-	for scanner.Scan() {
-		// Check if TBD is the first non-empty line - this is for extra caution.
-		if !emptyLineRegex.Match(scanner.Bytes()) {
-			if !versionToBeReleasedPlaceholderHeaderRegex.Match(scanner.Bytes()) {
-				return false, stacktrace.NewError("TBD header is either missing or is not the first non empty line in changelog.md")
-			}
-			tbdHeaderFound = true
-			break
-		}
-	}
+// Validates the changelog and returns the version bump that the changes in its TBD section call for
+func parseChangeLogFile(changelogFile []byte, changelogFormat changelog_ast.Format) (changelog_ast.BumpLevel, error) {
+	changelog := changelog_ast.Parse(changelogFile, changelogFormat)
 
 	// No TBD header was found because the file is empty.
-	if !tbdHeaderFound {
-		return false, stacktrace.NewError("Empty changelog file, please check the filepath again.")
+	if !changelog.HasContent() {
+		return changelog_ast.PatchBump, stacktrace.NewError("Empty changelog file, please check the filepath again.")
 	}
 
-	for scanner.Scan() {
-		if versionToBeReleasedPlaceholderHeaderRegex.Match(scanner.Bytes()) {
-			return false, stacktrace.NewError("Found more than %d TBD headers, there can only be #d TBD header in the changelog", expectedNumTBDHeaderLines)
-		}
-
-		// Scan file until next version header detected, searching for first not empty line along the way
-		if versionHeaderRegex.Match(scanner.Bytes()) {
-			foundLastReleasedVersionHeader = true
-			break
-		}
-
-		if !emptyLineRegex.Match(scanner.Bytes()) {
-			foundNonEmptyLineBeforeLastVersionHeader = true
-		}
-
-		// there exist breaking change header between TBD and last released version
-		if breakingChangesRegex.Match(scanner.Bytes()) {
-			isBreakingChange = true
-		}
+	// Check if TBD is the first non-empty line (or first version header, for formats that allow a title) - this is for extra caution.
+	if changelog.HasUnexpectedPreambleContent() || len(changelog.Versions) == 0 || !changelog.Versions[0].IsUnreleased() {
+		return changelog_ast.PatchBump, stacktrace.NewError("TBD header is either missing or is not the first non empty line in changelog.md")
 	}
+	unreleasedVersion := changelog.Versions[0]
 
-	if err := scanner.Err(); err != nil {
-		return false, stacktrace.Propagate(err, "An error occurred while scanning the bytes of the changelog file.")
+	if len(changelog.GetUnreleasedVersions()) > expectedNumTBDHeaderLines {
+		return changelog_ast.PatchBump, stacktrace.NewError("Found more than %d TBD headers, there can only be %d TBD header in the changelog", expectedNumTBDHeaderLines, expectedNumTBDHeaderLines)
 	}
 
-	if !foundLastReleasedVersionHeader {
-		return false, stacktrace.NewError("No previous release versions were detected in this changelog. Are you sure that the changelog is in sync with the release tags on this branch?")
+	if len(changelog.GetReleasedVersions()) == 0 {
+		return changelog_ast.PatchBump, stacktrace.NewError("No previous release versions were detected in this changelog. Are you sure that the changelog is in sync with the release tags on this branch?")
 	}
 
-	// if first non-empty line after TBD is the version line, it means that changelog.md is empty for upcoming release.
-	if !foundNonEmptyLineBeforeLastVersionHeader {
-		return false, stacktrace.NewError("changelog.md is empty for the current release, please check if the changes are merged and changelog.md is updated correctly.")
+	// if there's nothing between TBD and the last released version, it means that changelog.md is empty for upcoming release.
+	if !unreleasedVersion.HasContent() {
+		return changelog_ast.PatchBump, stacktrace.NewError("changelog.md is empty for the current release, please check if the changes are merged and changelog.md is updated correctly.")
 	}
 
-	return isBreakingChange, nil
+	return unreleasedVersion.GetBumpLevel(), nil
 }
 
 func init() {
 	ReleaseCmd.Flags().BoolVarP(&shouldBumpMajorVersion, "bump-major", bumpMajorFlagShortStr, bumpMajorFlagDefaultVal, "If set, in place of doing version autodetection based on the changelog, the major version (\"X\" in X.Y.Z) will be bumped")
+	ReleaseCmd.Flags().StringVar(&changelogFormatStr, changelogFormatFlagStr, changelogFormatFlagDefaultVal, "The format of the repo's changelog ("+strings.Join(changelog_ast.GetAllFormatStrs(), "|")+")")
+	ReleaseCmd.Flags().BoolVar(&shouldStampReleaseDate, stampReleaseDateFlagStr, stampReleaseDateFlagDefaultVal, "If set, the release date will be added to the header of the released version in the changelog (always done for changelog formats that require it)")
+	ReleaseCmd.Flags().StringVar(&releaseDateLayout, releaseDateLayoutFlagStr, releaseDateLayoutFlagDefaultVal, "The Go time layout that release dates in the changelog are written with")
+	ReleaseCmd.Flags().StringVar(&releaseDateTimezoneStr, releaseDateTimezoneFlagStr, releaseDateTimezoneFlagDefaultVal, "The IANA timezone (e.g. 'UTC' or 'America/New_York') that release dates in the changelog are written in")
+	ReleaseCmd.Flags().BoolVar(&shouldListContributors, listContributorsFlagStr, listContributorsFlagDefaultVal, "If set, the authors of the commits since the previous release will be credited in a '"+changelog_ast.ContributorsSectionTitle+"' section of the released version, deduplicated using the repo's '"+mailmapRelFilepath+"'")
+	ReleaseCmd.Flags().StringSliceVar(&contributorExcludePatterns, contributorExcludePatternFlagStr, contributorExcludePatternsFlagDefaultVal, "Regex matched against the name and email of commit authors to leave out of the contributors, e.g. bots (can be repeated)")
+	ReleaseCmd.Flags().BoolVar(&shouldAnnotateEntries, annotateEntriesFlagStr, annotateEntriesFlagDefaultVal, "If set, every entry of the released version will be annotated with the short SHA and PR of the commit that added it, found by blaming the changelog since the previous release")
+	ReleaseCmd.Flags().BoolVar(&shouldIsolate, isolateFlagStr, isolateFlagDefaultVal, "If set, the release is made in a temporary worktree at '"+originRemoteName+"/"+mainBranchName+"' that's thrown away afterwards, so the current checkout is never touched, even if the release fails")
+	ReleaseCmd.Flags().BoolVar(&shouldLinkReferences, linkReferencesFlagStr, linkReferencesFlagDefaultVal, "If set, bare '#123', 'owner/repo#45' and commit SHA references in the released section of the changelog will be turned into links to the '"+originRemoteName+"' repo")
 }
 
-func run(cmd *cobra.Command, args []string) error {
+func run(cmd *cobra.Command, args []string) (resultErr error) {
+	changelogFormat, err := changelog_ast.ParseFormat(changelogFormatStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing the changelog format")
+	}
+	releaseDateTimezone, err := time.LoadLocation(releaseDateTimezoneStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading release date timezone '%s'", releaseDateTimezoneStr)
+	}
+	var contributorExcludeRegexes []*regexp.Regexp
+	for _, pattern := range contributorExcludePatterns {
+		contributorExcludeRegex, err := regexp.Compile(pattern)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred compiling contributor exclude pattern '%s'", pattern)
+		}
+		contributorExcludeRegexes = append(contributorExcludeRegexes, contributorExcludeRegex)
+	}
+
 	logrus.Infof("Setting up authentication using provided token...")
-	token := os.Args[2]
+	token := args[0]
 	gitAuth := &http.BasicAuth{
 		Username: "git", // username doesn't matter
 		Password: token,
@@ -193,6 +212,13 @@ func run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred getting remote '%v' for repository; is the code pushed?", originRemoteName)
 	}
+	referencesRepoUrl := ""
+	if shouldLinkReferences {
+		referencesRepoUrl, err = getRepoWebUrl(originRemote.Config().URLs)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the web URL of remote '%s' to link changelog references to", originRemoteName)
+		}
+	}
 
 	logrus.Infof("Conducting pre release checks...")
 	worktree, err := repository.Worktree()
@@ -200,14 +226,16 @@ func run(cmd *cobra.Command, args []string) error {
 		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree of the repository.")
 	}
 
-	// Check no staged or unstaged changes exist on the branch before release
-	currWorktreeStatus, err := worktree.Status()
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
-	}
-	isClean := currWorktreeStatus.IsClean()
-	if !isClean {
-		return stacktrace.NewError("The branch contains modified files. Please ensure the working tree is clean before attempting to release. Currently the status is '%s'\n", currWorktreeStatus.String())
+	// Check no staged or unstaged changes exist on the branch before release, unless the release is made away from the user's checkout
+	if !shouldIsolate {
+		currWorktreeStatus, err := worktree.Status()
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred while trying to retrieve the status of the worktree of the repository.")
+		}
+		isClean := currWorktreeStatus.IsClean()
+		if !isClean {
+			return stacktrace.NewError("The branch contains modified files. Please ensure the working tree is clean before attempting to release. Currently the status is '%s'\n", currWorktreeStatus.String())
+		}
 	}
 
 	logrus.Infof("Fetching origin if needed...")
@@ -228,44 +256,126 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	logrus.Infof("Checking that %s and %s are in sync...", mainBranchName, originRemoteName)
-	// Check that local main and remote main are in sync
-	localMainBranchName := mainBranchName
 	remoteMainBranchName := fmt.Sprintf("%v/%v", originRemoteName, mainBranchName)
-	localMainHash, err := repository.ResolveRevision(plumbing.Revision(localMainBranchName))
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred parsing revision '%v'", localMainBranchName)
-	}
 	remoteMainHash, err := repository.ResolveRevision(plumbing.Revision(remoteMainBranchName))
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred parsing revision '%v'", remoteMainBranchName)
 	}
-	isLocalMainInSyncWithRemoteMain := localMainHash.String() == remoteMainHash.String()
-	if !isLocalMainInSyncWithRemoteMain {
-		return stacktrace.NewError("The local '%s' branch is not in sync with the '%s' '%s' branch. Must be in sync to conduct release process.", mainBranchName, originRemoteName, mainBranchName)
+	// An isolated release is made from the remote main, so the local main doesn't matter
+	if !shouldIsolate {
+		logrus.Infof("Checking that %s and %s are in sync...", mainBranchName, originRemoteName)
+		// Check that local main and remote main are in sync
+		localMainBranchName := mainBranchName
+		localMainHash, err := repository.ResolveRevision(plumbing.Revision(localMainBranchName))
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred parsing revision '%v'", localMainBranchName)
+		}
+		isLocalMainInSyncWithRemoteMain := localMainHash.String() == remoteMainHash.String()
+		if !isLocalMainInSyncWithRemoteMain {
+			return stacktrace.NewError("The local '%s' branch is not in sync with the '%s' '%s' branch. Must be in sync to conduct release process.", mainBranchName, originRemoteName, mainBranchName)
+		}
 	}
 
-	logrus.Infof("Checking out %s branch...", mainBranchName)
-	mainBranchRef := plumbing.ReferenceName(fmt.Sprintf("%s%s", headRef, mainBranchName))
-	err = worktree.Checkout(&git.CheckoutOptions{Branch: mainBranchRef})
+	// The release is made in either the user's checkout, or an isolated worktree that's thrown away afterwards
+	releaseDirpath := currentWorkingDirpath
+	releaseRepository := repository
+	if shouldIsolate {
+		logrus.Infof("Adding an isolated worktree at %s...", remoteMainBranchName)
+		isolated, err := createIsolatedWorktree(currentWorkingDirpath, *remoteMainHash)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred adding an isolated worktree to make the release in")
+		}
+		defer isolated.remove()
+		releaseDirpath = isolated.dirpath
+		releaseRepository = isolated.repository
+	} else {
+		logrus.Infof("Checking out %s branch...", mainBranchName)
+		mainBranchRef := plumbing.ReferenceName(fmt.Sprintf("%s%s", headRef, mainBranchName))
+		err = worktree.Checkout(&git.CheckoutOptions{Branch: mainBranchRef})
+		if err != nil {
+			return stacktrace.Propagate(err, "Missing required '%v' branch locally. Please run 'git checkout %v'", mainBranchName, mainBranchName)
+		}
+	}
+	releaseWorktree, err := releaseRepository.Worktree()
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred while trying to retrieve the worktree the release is made in.")
+	}
+
+	releaseConfig, err := loadReleaseConfig(releaseDirpath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred loading the release config.")
+	}
+
+	// Filled in as the release goes on, so on-failure hooks get as much of it as is known when the release fails
+	nextRelease := &releaseInfo{
+		previousVersion:      nil,
+		version:              nil,
+		bumpType:             "",
+		hasBreakingChanges:   false,
+		repoDirpath:          releaseDirpath,
+		parentCommitSha:      remoteMainHash.String(),
+		releaseNotesFilepath: "",
+		releaseCommitSha:     "",
+		tags:                 nil,
+	}
+
+	// The hooks are in the release config, so this is as early as they can run
+	// Defers run last-in, first-out, so this runs after the rollbacks registered further down have undone what they could
+	shouldRunOnFailureHooks := true
+	defer func() {
+		if shouldRunOnFailureHooks && resultErr != nil {
+			if err := runAllHooks(releaseConfig, onFailureHookStage, nextRelease, resultErr); err != nil {
+				logrus.Errorf("An error occurred running the '%s' hooks after the release failed:\n%v", onFailureHookStage, err)
+			}
+		}
+		// Removed here rather than where it's written, so the on-failure hooks can still read it
+		if nextRelease.releaseNotesFilepath != "" {
+			os.Remove(nextRelease.releaseNotesFilepath)
+		}
+	}()
+
+	// we have to manually populate the excludes because of https://github.com/kurtosis-tech/kudet/issues/22
+	// we should remove this piece when the above issue & bigger go-git issue gets resolved
+	logrus.Infof("Populating excludes for the worktree by parsing the .gitignore file")
+	gitIgnoreFilepath := path.Join(releaseDirpath, gitIgnoreRelFilepath)
+	gitIgnoreFile, err := os.Open(gitIgnoreFilepath)
 	if err != nil {
-		return stacktrace.Propagate(err, "Missing required '%v' branch locally. Please run 'git checkout %v'", mainBranchName, mainBranchName)
+		return stacktrace.Propagate(err, "An error occurred while reading the '%v' file", gitIgnoreFilepath)
+	}
+	defer gitIgnoreFile.Close()
+
+	gitIgnoreFileScanner := bufio.NewScanner(gitIgnoreFile)
+	// split the file by lines
+	gitIgnoreFileScanner.Split(bufio.ScanLines)
+	for gitIgnoreFileScanner.Scan() {
+		pattern := gitIgnoreFileScanner.Text()
+		if isWhiteSpaceOrComment(pattern) {
+			continue
+		}
+		releaseWorktree.Excludes = append(releaseWorktree.Excludes, gitignore.ParsePattern(pattern, emptyDomain))
 	}
 
 	// Conduct changelog file validation
-	changelogFilepath := path.Join(currentWorkingDirpath, relChangelogFilepath)
+	changelogFilepath := path.Join(releaseDirpath, relChangelogFilepath)
 	changelogFile, err := os.ReadFile(changelogFilepath)
 
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred attempting to read changelog file at provided path. Are you sure '%s' exists?", changelogFilepath)
 	}
 
-	hasBreakingChange, err := parseChangeLogFile(changelogFile)
+	bumpLevel, err := parseChangeLogFile(changelogFile, changelogFormat)
 
 	if err != nil {
 		return err
 	}
 
+	if len(releaseConfig.Gates) > 0 {
+		logrus.Infof("Running release gates...")
+		if err := runGates(releaseDirpath, releaseWorktree, releaseConfig.Gates); err != nil {
+			return stacktrace.Propagate(err, "The release gates didn't pass, so no version will be released.")
+		}
+	}
+
 	logrus.Infof("Finished prererelease checks.")
 
 	logrus.Infof("Guessing next release version...")
@@ -274,23 +384,55 @@ func run(cmd *cobra.Command, args []string) error {
 		return stacktrace.Propagate(err, "An error occurred getting the latest release version.")
 	}
 	var nextReleaseVersion semver.Version
+	var nextReleaseBumpType bumpType
 	if shouldBumpMajorVersion {
 		nextReleaseVersion = latestReleaseVersion.IncMajor()
+		nextReleaseBumpType = majorBumpType
 	} else {
-		if hasBreakingChange {
+		if bumpLevel == changelog_ast.MinorBump {
 			nextReleaseVersion = latestReleaseVersion.IncMinor()
+			nextReleaseBumpType = minorBumpType
 		} else {
 			nextReleaseVersion = latestReleaseVersion.IncPatch()
+			nextReleaseBumpType = patchBumpType
 		}
 	}
+	// The changelog was validated above, so its first version is the TBD section
+	unreleasedVersion := changelog_ast.Parse(changelogFile, changelogFormat).Versions[0]
+	nextRelease.previousVersion = latestReleaseVersion
+	nextRelease.version = &nextReleaseVersion
+	nextRelease.bumpType = nextReleaseBumpType
+	nextRelease.hasBreakingChanges = unreleasedVersion.HasBreakingChanges()
+
+	nextRelease.releaseNotesFilepath, err = writeReleaseNotesFile(unreleasedVersion)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred writing the release notes of release '%s' for the prerelease scripts", nextReleaseVersion.String())
+	}
+
+	if err := runHooks(releaseConfig, postVersionComputedHookStage, nextRelease); err != nil {
+		return stacktrace.Propagate(err, "A hook failed after computing release version '%s'; nothing was changed.", nextReleaseVersion.String())
+	}
+
+	logrus.Infof("Checking version files...")
+	if err := checkVersionFiles(releaseDirpath, releaseConfig.VersionFiles, nextRelease); err != nil {
+		return stacktrace.Propagate(err, "The version files aren't ready to release '%s'; nothing was changed.", nextReleaseVersion.String())
+	}
+
+	logrus.Infof("Checking prerelease scripts...")
+	if err := preflightPreReleaseScripts(releaseDirpath, releaseConfig, nextRelease); err != nil {
+		return stacktrace.Propagate(err, "The prerelease scripts aren't ready to release '%s'; nothing was changed.", nextReleaseVersion.String())
+	}
 
 	logrus.Infof("VERIFICATION: Release new version '%s'? (ENTER to continue, Ctrl-C to quit)", nextReleaseVersion.String())
 	_, err = fmt.Scanln()
 	if err != nil {
+		// Quitting isn't a failure of the release
+		shouldRunOnFailureHooks = false
 		return nil
 	}
 
-	shouldResetLocalBranch := true
+	// An isolated worktree is simply thrown away, so only the user's checkout needs resetting
+	shouldResetLocalBranch := !shouldIsolate
 	defer func() {
 		if shouldResetLocalBranch {
 			// git reset --hard origin/main
@@ -301,62 +443,84 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	// The changelog is blamed at the commit the release is made on, which is why the release fails if anything changes the changelog before it's updated
+	var changelogLineAttributions map[int]*lineAttribution
+	if shouldAnnotateEntries {
+		logrus.Infof("Blaming the changelog to attribute its entries...")
+		changelogLineAttributions, err = getChangelogLineAttributions(repository, *remoteMainHash, relChangelogFilepath)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred attributing the changelog entries to commits")
+		}
+	}
+
+	if len(releaseConfig.VersionFiles) > 0 {
+		logrus.Infof("Updating version files...")
+		if err := updateVersionFiles(releaseDirpath, releaseConfig.VersionFiles, nextRelease); err != nil {
+			return stacktrace.Propagate(err, "An error occurred updating the version files to release '%s'", nextReleaseVersion.String())
+		}
+	}
+
 	logrus.Infof("Running prerelease scripts...")
-	err = runPreReleaseScripts(currentWorkingDirpath, nextReleaseVersion.String())
+	err = runPreReleaseScripts(releaseDirpath, releaseConfig, nextRelease)
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred while running prerelease scripts.")
 	}
 
 	logrus.Infof("Updating the changelog...")
-	err = updateChangelog(changelogFilepath, nextReleaseVersion.String())
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred while updating the changelog file at '%s'", changelogFilepath)
+	releaseDate := ""
+	if shouldStampReleaseDate || changelogFormat.IsReleaseDateRequired() {
+		releaseDate = formatReleaseDate(time.Now(), releaseDateLayout, releaseDateTimezone)
 	}
-
-	// we have to manually populate the excludes because of https://github.com/kurtosis-tech/kudet/issues/22
-	// we should remove this piece when the above issue & bigger go-git issue gets resolved
-	logrus.Infof("Populating excludes for the worktree by parsing the .gitignore file")
-	gitIgnoreFile, err := os.Open(gitIgnoreRelFilepath)
+	var contributors []string
+	if shouldListContributors {
+		contributors, err = getReleaseContributors(repository, *remoteMainHash, path.Join(releaseDirpath, mailmapRelFilepath), contributorExcludeRegexes)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred getting the contributors to release '%s'", nextReleaseVersion.String())
+		}
+	}
+	if shouldAnnotateEntries {
+		if err := checkChangelogUnchangedSinceBlame(changelogFilepath, changelogFile); err != nil {
+			return stacktrace.Propagate(err, "The entries of release '%s' can't be annotated", nextReleaseVersion.String())
+		}
+	}
+	err = updateChangelog(changelogFilepath, changelogFormat, nextReleaseVersion.String(), releaseDate, changelogLineAttributions, referencesRepoUrl, contributors)
 	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred while reading the '%v' file", gitIgnoreRelFilepath)
+		return stacktrace.Propagate(err, "An error occurred while updating the changelog file at '%s'", changelogFilepath)
 	}
-	defer gitIgnoreFile.Close()
 
-	gitIgnoreFileScanner := bufio.NewScanner(gitIgnoreFile)
-	// split the file by lines
-	gitIgnoreFileScanner.Split(bufio.ScanLines)
-	for gitIgnoreFileScanner.Scan() {
-		pattern := gitIgnoreFileScanner.Text()
-		if isWhiteSpaceOrComment(pattern) {
-			continue
-		}
-		worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern(pattern, emptyDomain))
+	alwaysAllowedFilepaths := append([]string{relChangelogFilepath}, getVersionFilepaths(releaseConfig.VersionFiles)...)
+	if err := runPreCommitHooksAndCheckChanges(releaseConfig, nextRelease, releaseWorktree, alwaysAllowedFilepaths); err != nil {
+		return stacktrace.Propagate(err, "Release '%s' can't be committed", nextReleaseVersion.String())
 	}
 
 	logrus.Infof("Committing changes locally...")
-	err = worktree.AddWithOptions(&git.AddOptions{All: true})
+	err = releaseWorktree.AddWithOptions(&git.AddOptions{All: true})
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred while adding files to the staging area")
 	}
 
 	commitMsg := fmt.Sprintf("Finalize changes for release version '%s'", nextReleaseVersion.String())
-	_, err = worktree.Commit(commitMsg, &git.CommitOptions{
+	releaseCommitHash, err := releaseWorktree.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  name,
 			Email: email,
 			When:  time.Now(),
 		},
 	})
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred committing the changes for release '%s'", nextReleaseVersion.String())
+	}
+	nextRelease.releaseCommitSha = releaseCommitHash.String()
 
 	logrus.Infof("Setting next release version tag...")
 	// Set next release version tag
 	releaseTag := nextReleaseVersion.String()
 	vReleaseTag := fmt.Sprintf("v%s", nextReleaseVersion.String())
-	head, err := repository.Head()
+	head, err := releaseRepository.Head()
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred while attempting to get the ref to HEAD of the local repository.")
 	}
-	_, err = repository.CreateTag(releaseTag, head.Hash(), &git.CreateTagOptions{
+	_, err = releaseRepository.CreateTag(releaseTag, head.Hash(), &git.CreateTagOptions{
 		Message: releaseTag,
 	})
 	if err != nil {
@@ -366,13 +530,13 @@ func run(cmd *cobra.Command, args []string) error {
 	defer func() {
 		if shouldDeleteLocalReleaseTag {
 			// git tag -d
-			err = repository.DeleteTag(releaseTag)
+			err = releaseRepository.DeleteTag(releaseTag)
 			if err != nil {
 				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", releaseTag, err)
 			}
 		}
 	}()
-	_, err = repository.CreateTag(vReleaseTag, head.Hash(), &git.CreateTagOptions{
+	_, err = releaseRepository.CreateTag(vReleaseTag, head.Hash(), &git.CreateTagOptions{
 		Message: vReleaseTag,
 	})
 	if err != nil {
@@ -382,12 +546,17 @@ func run(cmd *cobra.Command, args []string) error {
 	defer func() {
 		if shouldDeleteLocalVPrefixedReleaseTag {
 			// git tag -d
-			err = repository.DeleteTag(vReleaseTag)
+			err = releaseRepository.DeleteTag(vReleaseTag)
 			if err != nil {
 				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to undo creation of tag '%s'. Please run 'git tag -d %s' to delete the tag manually.", vReleaseTag, vReleaseTag)
 			}
 		}
 	}()
+	nextRelease.tags = []string{releaseTag, vReleaseTag}
+
+	if err := runHooks(releaseConfig, postTagHookStage, nextRelease); err != nil {
+		return stacktrace.Propagate(err, "A hook failed after tagging release '%s'", nextReleaseVersion.String())
+	}
 
 	// The order in which we push resources to remote is: vReleaseTag -> Commits -> Release Tag
 	// This is important because we push in order of easiest to reverse to harder to reverse in case of failures
@@ -399,7 +568,7 @@ func run(cmd *cobra.Command, args []string) error {
 		RefSpecs:   []config.RefSpec{config.RefSpec(vReleaseTagRefSpec)},
 		Auth:       gitAuth,
 	}
-	if err = repository.Push(pushVPrefixedReleaseTagOpts); err != nil {
+	if err = releaseRepository.Push(pushVPrefixedReleaseTagOpts); err != nil {
 		logrus.Errorf("An error occurred while pushing release tag: '%s' to '%s'.", vReleaseTag, remoteMainBranchName)
 	}
 	shouldDeleteRemoteVPrefixedReleaseTag := true
@@ -412,7 +581,7 @@ func run(cmd *cobra.Command, args []string) error {
 				RefSpecs:   []config.RefSpec{config.RefSpec(emptyVReleaseTagRefSpec)},
 				Auth:       gitAuth,
 			}
-			err = repository.Push(deleteVPrefixedReleaseTagPushOpts)
+			err = releaseRepository.Push(deleteVPrefixedReleaseTagPushOpts)
 			if err != nil {
 				logrus.Errorf("ACTION REQUIRED: An error occurred attempting to delete tag '%s' from '%s'. Please run 'git push --delete %s %s' to delete the tag manually.", vReleaseTag, originRemoteName, originRemoteName, vReleaseTag)
 			}
@@ -421,7 +590,10 @@ func run(cmd *cobra.Command, args []string) error {
 
 	logrus.Infof("Pushing release changes to '%s'...", remoteMainBranchName)
 	pushCommitOpts := &git.PushOptions{RemoteName: originRemoteName, Auth: gitAuth}
-	if err = repository.Push(pushCommitOpts); err != nil {
+	if shouldIsolate {
+		pushCommitOpts.RefSpecs = []config.RefSpec{config.RefSpec(fmt.Sprintf(isolatedMainBranchPushRefSpecFmt, mainBranchName))}
+	}
+	if err = releaseRepository.Push(pushCommitOpts); err != nil {
 		return stacktrace.Propagate(err, "An error occurred while pushing release changes to '%s'", remoteMainBranchName)
 	}
 	shouldWarnAboutUndoingRemotePush := true
@@ -438,7 +610,7 @@ func run(cmd *cobra.Command, args []string) error {
 		RefSpecs:   []config.RefSpec{config.RefSpec(releaseTagRefSpec)},
 		Auth:       gitAuth,
 	}
-	if err = repository.Push(pushReleaseTagOpts); err != nil {
+	if err = releaseRepository.Push(pushReleaseTagOpts); err != nil {
 		return stacktrace.Propagate(err, "An error occurred while pushing release tag: '%s' to '%s'", releaseTag, remoteMainBranchName)
 	}
 
@@ -447,7 +619,16 @@ func run(cmd *cobra.Command, args []string) error {
 	shouldDeleteLocalVPrefixedReleaseTag = false
 	shouldDeleteRemoteVPrefixedReleaseTag = false
 	shouldWarnAboutUndoingRemotePush = false
+	shouldRunOnFailureHooks = false
+
+	if shouldIsolate {
+		logrus.Infof("The release was made in an isolated worktree, so your checkout wasn't changed; run 'git pull %s %s' to get the release commit.", originRemoteName, mainBranchName)
+	}
 
+	// The release is out, so failing hooks can't undo it and are reported on their own
+	if err := runAllHooks(releaseConfig, postPushHookStage, nextRelease, nil); err != nil {
+		return stacktrace.Propagate(err, "Release '%s' succeeded, but some of its '%s' hooks failed", nextReleaseVersion.String(), postPushHookStage)
+	}
 	logrus.Infof("Release success.")
 	return nil
 }
@@ -481,120 +662,86 @@ func determineShouldFetch(lastFetchedFilepath string) (bool, error) {
 	return time.Now().After(noFetchNeededBefore), nil
 }
 
-func getLatestReleaseVersion(repo *git.Repository) (*semver.Version, error) {
-	tagrefs, err := repo.Tags()
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "An error occurred while retrieving tags for repository.")
-	}
-
-	// Trim tagrefs and filter for only tags with X.Y.Z version format
-	var allTagSemVers []*semver.Version
-	err = tagrefs.ForEach(func(tagref *plumbing.Reference) error {
-		tagName := tagref.Name().String()
-		tagName = strings.ReplaceAll(tagName, tagsPrefix, "")
-
-		if semverRegex.Match([]byte(tagName)) {
-			tagSemVer, err := semver.StrictNewVersion(tagName)
-			if err != nil {
-				return stacktrace.Propagate(err, "An error occurred parsing '%s' tag into a semver object.", tagName)
-			}
-			allTagSemVers = append(allTagSemVers, tagSemVer)
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "An error occurred while iterating through tagrefs in the repository.")
+// Turns the first URL of a remote, e.g. 'git@github.com:owner/repo.git', into the URL of the repo's web page, e.g. 'https://github.com/owner/repo'
+func getRepoWebUrl(remoteUrls []string) (string, error) {
+	if len(remoteUrls) == 0 {
+		return "", stacktrace.NewError("The remote has no URLs")
 	}
+	remoteUrl := remoteUrls[0]
 
-	var latestReleaseTagSemVer *semver.Version
-	if len(allTagSemVers) == 0 {
-		latestReleaseTagSemVer, err = semver.StrictNewVersion(noPreviousVersion)
-		if err != nil {
-			return nil, stacktrace.Propagate(err, "An error occurred creating '%s' semantic version.", noPreviousVersion)
-		}
+	var hostAndPath string
+	if parsedUrl, err := url.Parse(remoteUrl); err == nil && parsedUrl.Scheme != "" && parsedUrl.Host != "" {
+		hostAndPath = parsedUrl.Hostname() + parsedUrl.Path
+	} else if matches := scpLikeUrlRegex.FindStringSubmatch(remoteUrl); matches != nil {
+		hostAndPath = matches[1] + "/" + strings.TrimPrefix(matches[2], "/")
 	} else {
-		sort.Sort(sort.Reverse(semver.Collection(allTagSemVers)))
-		latestReleaseTagSemVer = allTagSemVers[0]
+		return "", stacktrace.NewError("Remote URL '%s' isn't a URL that a repo web page can be derived from", remoteUrl)
 	}
-
-	return latestReleaseTagSemVer, nil
+	return httpsUrlPrefix + strings.TrimSuffix(strings.TrimSuffix(hostAndPath, "/"), gitSuffix), nil
 }
 
-func runPreReleaseScripts(preReleaseScriptsDirpath string, releaseVersion string) error {
-	preReleaseScriptsFilepath := path.Join(preReleaseScriptsDirpath, preReleaseScriptsFilename)
-	preReleaseScriptsFile, err := os.ReadFile(preReleaseScriptsFilepath)
+func getLatestReleaseVersion(repo *git.Repository) (*semver.Version, error) {
+	releaseTags, err := release_tags.GetReleaseTags(repo)
 	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to open file at provided path. Are you sure '%s' exists?", preReleaseScriptsFilepath)
+		return nil, stacktrace.Propagate(err, "An error occurred getting the release tags of the repository.")
 	}
 
-	lines := bytes.Split(preReleaseScriptsFile, []byte("\n"))
-	for _, line := range lines {
-		scriptFilepath := string(line)
-		if strings.TrimSpace(scriptFilepath) == "" {
-			continue
-		}
-		scriptCmdString := path.Join(preReleaseScriptsDirpath, scriptFilepath)
-		scriptCmd := exec.Command(scriptCmdString, releaseVersion)
-
-		if err := scriptCmd.Run(); err != nil {
-			castedErr, ok := err.(*exec.ExitError)
-			if !ok {
-				return stacktrace.Propagate(err, "Pre release script command '%s %s' failed with an unrecognized error", scriptCmdString, releaseVersion)
-			}
-			return stacktrace.NewError("Pre release script command '%s %s' returned logs:\n%s", scriptCmdString, releaseVersion, string(castedErr.Stderr))
+	if len(releaseTags) == 0 {
+		latestReleaseTagSemVer, err := semver.StrictNewVersion(noPreviousVersion)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred creating '%s' semantic version.", noPreviousVersion)
 		}
+		return latestReleaseTagSemVer, nil
 	}
-
-	return nil
+	return releaseTags[len(releaseTags)-1].Version, nil
 }
 
-func updateChangelog(changelogFilepath string, releaseVersion string) error {
+// An empty release date means the released version's header won't be dated
+// Entries are annotated with the attributions of their bullet lines, if any
+// If the references repo URL isn't empty, references in the released section (including the attributions) will be linked to it
+// If there are contributors, they'll be listed at the end of the released section
+func updateChangelog(
+	changelogFilepath string,
+	changelogFormat changelog_ast.Format,
+	releaseVersion string,
+	releaseDate string,
+	lineAttributions map[int]*lineAttribution,
+	referencesRepoUrl string,
+	contributors []string,
+) error {
+	changelogFileInfo, err := os.Stat(changelogFilepath)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to retrieve file info for the changelog file at '%s'", changelogFilepath)
+	}
 	changelogFile, err := os.ReadFile(changelogFilepath)
 	if err != nil {
 		return stacktrace.Propagate(err, "An error occurred attempting to open changelog file at provided path. Are you sure '%s' exists?", changelogFilepath)
 	}
-	lines := bytes.Split(changelogFile, []byte("\n"))
-	emptyLine := []byte("\n")
 
-	// Check that first line contains version to be released placeholder header
-	if !versionToBeReleasedPlaceholderHeaderRegex.Match(lines[0]) {
-		return stacktrace.NewError("No '%s' found in the first line of the changelog. Check the changelog at '%s' is in the correct format.", versionToBeReleasedPlaceholderHeaderStr, changelogFilepath)
-	}
-	// Create new update changelog file
-	updatedChangelogFile, err := os.Create(changelogFilepath)
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to create the updated changelog file at '%s'", changelogFilepath)
-	}
-	// Write version to be released placeholder header as the first line
-	_, err = updatedChangelogFile.Write([]byte(string(lines[0]) + "\n"))
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to write '%s' to the updated changelog file at '%s'", versionToBeReleasedPlaceholderHeaderStr, changelogFilepath)
+	changelog := changelog_ast.Parse(changelogFile, changelogFormat)
+	if err := changelog.ReleaseUnreleasedVersion(releaseVersion, releaseDate); err != nil {
+		return stacktrace.Propagate(err, "An error occurred releasing the TBD section as version '%s'. Check the changelog at '%s' is in the correct format.", releaseVersion, changelogFilepath)
 	}
-	// Write an empty line
-	_, err = updatedChangelogFile.Write(emptyLine)
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to write empty line to the updated changelog file at '%s'", changelogFilepath)
-	}
-	// Write the new version header
-	releaseVersionHeader := fmt.Sprintf("%s %s", sectionHeaderPrefix, releaseVersion)
-	_, err = updatedChangelogFile.Write([]byte(releaseVersionHeader))
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to write '%s' to the updated changelog file at '%s'", versionToBeReleasedPlaceholderHeaderStr, changelogFilepath)
-	}
-	// Write another empty line
-	_, err = updatedChangelogFile.Write(emptyLine)
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to write an empty line after the new version header to the updated changelog file at '%s'", changelogFilepath)
+	annotateReleasedEntries(changelog.GetVersion(releaseVersion), lineAttributions)
+	if referencesRepoUrl != "" {
+		if err := changelog.GetVersion(releaseVersion).LinkReferences(referencesRepoUrl); err != nil {
+			return stacktrace.Propagate(err, "An error occurred linking the references in the changelog section of version '%s'", releaseVersion)
+		}
 	}
-	// Write the rest of the lines
-	_, err = updatedChangelogFile.Write(bytes.Join(lines[1:], []byte("\n")))
-	if err != nil {
-		return stacktrace.Propagate(err, "An error occurred attempting to append existing the existing changelog file contents to the updated changelog file at '%s':\n", changelogFilepath)
+	if len(contributors) > 0 {
+		changelog.GetVersion(releaseVersion).SetContributors(contributors)
 	}
 
+	if err := os.WriteFile(changelogFilepath, changelog.Bytes(), changelogFileInfo.Mode()); err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to write the updated changelog file at '%s'", changelogFilepath)
+	}
 	return nil
 }
 
+func formatReleaseDate(releaseTime time.Time, layout string, timezone *time.Location) string {
+	return releaseTime.In(timezone).Format(layout)
+}
+
 func isWhiteSpaceOrComment(pattern string) bool {
 	if strings.HasPrefix(pattern, gitIgnoreCommentCharacter) {
 		return true
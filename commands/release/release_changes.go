@@ -0,0 +1,105 @@
+package release
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/path_globs"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"strings"
+)
+
+type fileChangeKind string
+
+const (
+	modifiedFileChangeKind fileChangeKind = "modified"
+	addedFileChangeKind    fileChangeKind = "added"
+	deletedFileChangeKind  fileChangeKind = "deleted"
+)
+
+// A file that differs between the worktree and HEAD
+type fileChange struct {
+	// Relative to the root of the repo
+	filepath string
+
+	kind fileChangeKind
+}
+
+// Returns the files that differ between the worktree and HEAD, sorted by path
+// Ignored files aren't included, so the worktree's excludes must be set up before calling this
+func getWorktreeChanges(worktree *git.Worktree) ([]*fileChange, error) {
+	worktreeStatus, err := worktree.Status()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting the status of the worktree")
+	}
+	var result []*fileChange
+	for filepath, fileStatus := range worktreeStatus {
+		statusCode := fileStatus.Worktree
+		if statusCode == git.Unmodified {
+			statusCode = fileStatus.Staging
+		}
+		var kind fileChangeKind
+		switch statusCode {
+		case git.Unmodified:
+			continue
+		case git.Untracked, git.Added:
+			kind = addedFileChangeKind
+		case git.Deleted:
+			kind = deletedFileChangeKind
+		default:
+			kind = modifiedFileChangeKind
+		}
+		result = append(result, &fileChange{
+			filepath: filepath,
+			kind:     kind,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].filepath < result[j].filepath
+	})
+	return result, nil
+}
+
+// Logs every change, and returns an error listing the changes outside the allowed globs
+// A nil list of allowed globs allows every change; the always allowed files are the ones kudet changes itself
+func checkReleaseChanges(changes []*fileChange, allowedGlobs []string, alwaysAllowedFilepaths []string) error {
+	logrus.Infof("The release commit will have %d changed file(s):", len(changes))
+	for _, change := range changes {
+		logrus.Infof("  %-8s %s", change.kind, change.filepath)
+	}
+	if allowedGlobs == nil {
+		return nil
+	}
+
+	allowedRegexes := path_globs.AllToRegexes(allowedGlobs)
+	var disallowedChangeDescriptions []string
+	for _, change := range changes {
+		if isAlwaysAllowed(change.filepath, alwaysAllowedFilepaths) || path_globs.MatchesAny(change.filepath, allowedRegexes) {
+			continue
+		}
+		disallowedChangeDescriptions = append(disallowedChangeDescriptions, string(change.kind)+" "+change.filepath)
+	}
+	if len(disallowedChangeDescriptions) > 0 {
+		return stacktrace.NewError(
+			"The pre release scripts or pre-commit hooks changed files outside of the '%s' of the release config (%s):\n%s",
+			releaseConfigAllowedChangesKey,
+			strings.Join(allowedGlobs, ", "),
+			strings.Join(disallowedChangeDescriptions, "\n"),
+		)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func isAlwaysAllowed(filepath string, alwaysAllowedFilepaths []string) bool {
+	for _, alwaysAllowedFilepath := range alwaysAllowedFilepaths {
+		if filepath == alwaysAllowedFilepath {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package release
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWorktreeChanges(t *testing.T) {
+	dirpath := t.TempDir()
+	repo, err := git.PlainInit(dirpath, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	for _, filepath := range []string{"modified.txt", "deleted.txt", "untouched.txt"} {
+		require.NoError(t, os.WriteFile(path.Join(dirpath, filepath), []byte("original\n"), 0644))
+		_, err = worktree.Add(filepath)
+		require.NoError(t, err)
+	}
+	_, err = worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "modified.txt"), []byte("changed\n"), 0644))
+	require.NoError(t, os.Remove(path.Join(dirpath, "deleted.txt")))
+	require.NoError(t, os.MkdirAll(path.Join(dirpath, "new"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "new", "added.txt"), []byte("new\n"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "build.out"), []byte("ignored\n"), 0644))
+	worktree.Excludes = append(worktree.Excludes, gitignore.ParsePattern("*.out", nil))
+
+	changes, err := getWorktreeChanges(worktree)
+	require.NoError(t, err)
+	require.Equal(t, []*fileChange{
+		{filepath: "deleted.txt", kind: deletedFileChangeKind},
+		{filepath: "modified.txt", kind: modifiedFileChangeKind},
+		{filepath: "new/added.txt", kind: addedFileChangeKind},
+	}, changes)
+}
+
+func TestCheckReleaseChanges(t *testing.T) {
+	changes := []*fileChange{
+		{filepath: "docs/changelog.md", kind: modifiedFileChangeKind},
+		{filepath: "package.json", kind: modifiedFileChangeKind},
+		{filepath: "api/version.go", kind: modifiedFileChangeKind},
+		{filepath: "dist/app.tar.gz", kind: addedFileChangeKind},
+		{filepath: "README.md", kind: deletedFileChangeKind},
+	}
+	alwaysAllowedFilepaths := []string{"docs/changelog.md"}
+
+	require.NoError(t, checkReleaseChanges(changes, nil, alwaysAllowedFilepaths), "Any change should be allowed without an allowlist")
+	require.NoError(t, checkReleaseChanges(changes, []string{"package.json", "**/version.go", "dist/", "*.md"}, alwaysAllowedFilepaths))
+
+	err := checkReleaseChanges(changes, []string{"package.json", "**/version.go"}, alwaysAllowedFilepaths)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "added dist/app.tar.gz\ndeleted README.md")
+	require.NotContains(t, err.Error(), "docs/changelog.md")
+
+	err = checkReleaseChanges(changes[:1], []string{}, alwaysAllowedFilepaths)
+	require.NoError(t, err, "The files kudet changes itself should be allowed even by an empty allowlist")
+}
@@ -0,0 +1,498 @@
+package release
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/path_globs"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/version_file_updater"
+	"github.com/kurtosis-tech/stacktrace"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// this is relative to the root of the target repo
+	releaseConfigFilename = ".kudet-release.yml"
+	// The plain list of script paths that came before the release config, still read if a repo has no release config
+	preReleaseScriptsFilename = ".pre-release-scripts.txt"
+
+	releaseConfigGatesKey              = "gates"
+	releaseConfigHooksKey              = "hooks"
+	releaseConfigPreReleaseScriptsKey  = "pre-release-scripts"
+	releaseConfigAllowedChangesKey     = "allowed-changes"
+	releaseConfigMaxParallelScriptsKey = "max-parallel-scripts"
+	releaseConfigVersionFilesKey       = "version-files"
+	releaseConfigStepOutputsKey        = "outputs"
+
+	defaultMaxParallelScripts = 1
+
+	// Replaced with the release version in the args of a step
+	releaseVersionArgPlaceholder = "{version}"
+)
+
+type bumpType string
+
+const (
+	majorBumpType bumpType = "major"
+	minorBumpType bumpType = "minor"
+	patchBumpType bumpType = "patch"
+)
+
+var allBumpTypes = []bumpType{majorBumpType, minorBumpType, patchBumpType}
+
+type releaseConfig struct {
+	// Read-only checks (e.g. tests) that must pass before a version is proposed
+	Gates []*commandStep `yaml:"gates"`
+
+	// Commands run at stages of the release, which get the release context as JSON on stdin
+	Hooks map[hookStage][]*commandStep `yaml:"hooks"`
+
+	PreReleaseScripts []*scriptStep `yaml:"pre-release-scripts"`
+
+	// How many pre-release scripts can run at the same time; defaults to 1, which runs them one after another in order
+	MaxParallelScripts int `yaml:"max-parallel-scripts"`
+
+	// Globs of the files the pre-release scripts may change, relative to the root of the repo; if not set, they may change any file
+	AllowedChanges []string `yaml:"allowed-changes"`
+
+	// Files kudet updates to the release version itself, before the pre-release scripts run
+	VersionFiles []*versionFile `yaml:"version-files"`
+}
+
+// A file with a line holding the version, like 'kudet update-version-in-file' updates
+type versionFile struct {
+	// Relative to the root of the repo
+	Path string `yaml:"path"`
+
+	// A regex matching exactly one line of the file, where '%s' stands for the version, e.g. '"version": "%s"'
+	Pattern string `yaml:"pattern"`
+}
+
+// What a step of the release runs, and how
+type scriptCommand struct {
+	// A path relative to the root of the repo if it contains a '/', or else a program looked up in the PATH
+	Command string `yaml:"command"`
+
+	// For pre-release scripts, the release version is the only argument if this isn't set, and occurrences of '{version}' are replaced with the release version
+	Args []string `yaml:"args"`
+
+	// Relative to the root of the repo, which is also the default
+	WorkingDirectory string `yaml:"working-directory"`
+
+	// Added to the environment kudet was run with
+	Env map[string]string `yaml:"env"`
+
+	// Zero means the command can take as long as it needs; otherwise e.g. '90s' or '5m'
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type scriptStep struct {
+	// Defaults to the command; other steps refer to the step by its name
+	Name string `yaml:"name"`
+
+	// The names of the steps that must have finished before this one starts; steps that don't need each other may run at the same time
+	Needs []string `yaml:"needs"`
+
+	scriptCommand `yaml:",inline"`
+
+	// How many more times the step is run if it fails
+	Retries int `yaml:"retries"`
+
+	// If set, a failure of the step is logged rather than stopping the release
+	ContinueOnError bool `yaml:"continue-on-error"`
+
+	// If not set, the step always runs
+	When *stepConditions `yaml:"when"`
+
+	// If set, the command is also run with '--check' as its only argument before the release is confirmed, to validate its preconditions
+	Check bool `yaml:"check"`
+
+	// Globs of the files the step writes, relative to the root of the repo; steps that may run at the same time must list them, and can't write the same files
+	Outputs []string `yaml:"outputs"`
+}
+
+// A command that's run on its own, like a gate or a hook
+type commandStep struct {
+	// Defaults to the command
+	Name string `yaml:"name"`
+
+	scriptCommand `yaml:",inline"`
+}
+
+type stepConditions struct {
+	// The step only runs for releases with one of these bump types
+	Bump []bumpType `yaml:"bump"`
+}
+
+// Reads the release config from the root of the repo, falling back to the pre-release scripts list if there's no release config
+func loadReleaseConfig(repoDirpath string) (*releaseConfig, error) {
+	releaseConfigFilepath := path.Join(repoDirpath, releaseConfigFilename)
+	preReleaseScriptsFilepath := path.Join(repoDirpath, preReleaseScriptsFilename)
+	releaseConfigFile, err := os.ReadFile(releaseConfigFilepath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the release config at '%s'", releaseConfigFilepath)
+	}
+	hasReleaseConfig := err == nil
+	preReleaseScriptsFile, err := os.ReadFile(preReleaseScriptsFilepath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, stacktrace.Propagate(err, "An error occurred reading the pre-release scripts list at '%s'", preReleaseScriptsFilepath)
+	}
+	hasPreReleaseScripts := err == nil
+
+	if hasReleaseConfig && hasPreReleaseScripts {
+		return nil, stacktrace.NewError("Both a release config at '%s' and a pre-release scripts list at '%s' were found; move the scripts into the '%s' of the release config and delete the list", releaseConfigFilepath, preReleaseScriptsFilepath, releaseConfigPreReleaseScriptsKey)
+	}
+	if hasPreReleaseScripts {
+		return parsePreReleaseScriptsList(preReleaseScriptsFile), nil
+	}
+	if !hasReleaseConfig {
+		return nil, stacktrace.NewError("Neither a release config at '%s' nor a pre-release scripts list at '%s' was found. Are you sure this is the root of a repo set up for releasing?", releaseConfigFilepath, preReleaseScriptsFilepath)
+	}
+
+	result, err := parseReleaseConfig(releaseConfigFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred parsing the release config at '%s'", releaseConfigFilepath)
+	}
+	return result, nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseReleaseConfig(releaseConfigFile []byte) (*releaseConfig, error) {
+	result := &releaseConfig{
+		Gates:              nil,
+		Hooks:              nil,
+		PreReleaseScripts:  nil,
+		MaxParallelScripts: 0,
+		AllowedChanges:     nil,
+		VersionFiles:       nil,
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(releaseConfigFile))
+	// Typos in keys would otherwise silently turn into steps that don't do what they say
+	decoder.KnownFields(true)
+	if err := decoder.Decode(result); err != nil && !errors.Is(err, io.EOF) {
+		return nil, stacktrace.Propagate(err, "An error occurred decoding the release config")
+	}
+
+	if result.MaxParallelScripts < 0 {
+		return nil, stacktrace.NewError("The '%s' of the release config can't be negative, but was '%d'", releaseConfigMaxParallelScriptsKey, result.MaxParallelScripts)
+	}
+	if result.MaxParallelScripts == 0 {
+		result.MaxParallelScripts = defaultMaxParallelScripts
+	}
+	if err := validateCommandSteps(result.Gates, releaseConfigGatesKey); err != nil {
+		return nil, stacktrace.Propagate(err, "The release config has an invalid gate")
+	}
+	for stage, hooks := range result.Hooks {
+		if !isValidHookStage(stage) {
+			return nil, stacktrace.NewError("The '%s' of the release config have unrecognized stage '%s'; valid stages are: %s", releaseConfigHooksKey, stage, getAllHookStagesStr())
+		}
+		if err := validateCommandSteps(hooks, fmt.Sprintf("%s.%s", releaseConfigHooksKey, stage)); err != nil {
+			return nil, stacktrace.Propagate(err, "The release config has an invalid '%s' hook", stage)
+		}
+	}
+	for idx, step := range result.PreReleaseScripts {
+		if step == nil || strings.TrimSpace(step.Command) == "" {
+			return nil, stacktrace.NewError("Step #%d of '%s' has no command", idx+1, releaseConfigPreReleaseScriptsKey)
+		}
+		if step.Name == "" {
+			step.Name = step.Command
+		}
+		if step.Timeout < 0 {
+			return nil, stacktrace.NewError("Step '%s' has negative timeout '%s'", step.Name, step.Timeout)
+		}
+		if step.Retries < 0 {
+			return nil, stacktrace.NewError("Step '%s' has negative retry count '%d'", step.Name, step.Retries)
+		}
+		if step.When != nil {
+			for _, conditionBumpType := range step.When.Bump {
+				if !isValidBumpType(conditionBumpType) {
+					return nil, stacktrace.NewError("Step '%s' has unrecognized bump type '%s' in its conditions; valid bump types are: %s", step.Name, conditionBumpType, getAllBumpTypesStr())
+				}
+			}
+		}
+	}
+	if err := validateStepDependencies(result.PreReleaseScripts); err != nil {
+		return nil, stacktrace.Propagate(err, "The '%s' of the release config have invalid dependencies", releaseConfigPreReleaseScriptsKey)
+	}
+	if err := validateStepOutputs(result.PreReleaseScripts, result.MaxParallelScripts); err != nil {
+		return nil, stacktrace.Propagate(err, "The '%s' of the release config could write the same files at the same time", releaseConfigPreReleaseScriptsKey)
+	}
+	// Updates are computed from the files before any is written, so two updates of the same file would overwrite each other
+	versionFilepaths := map[string]bool{}
+	for idx, file := range result.VersionFiles {
+		if file == nil || strings.TrimSpace(file.Path) == "" {
+			return nil, stacktrace.NewError("Entry #%d of '%s' has no path", idx+1, releaseConfigVersionFilesKey)
+		}
+		file.Path = path.Clean(file.Path)
+		if path.IsAbs(file.Path) || file.Path == ".." || strings.HasPrefix(file.Path, "../") {
+			return nil, stacktrace.NewError("Version file '%s' must be inside the repo, relative to its root", file.Path)
+		}
+		if versionFilepaths[file.Path] {
+			return nil, stacktrace.NewError("Version file '%s' is listed more than once in '%s'", file.Path, releaseConfigVersionFilesKey)
+		}
+		versionFilepaths[file.Path] = true
+		if !strings.Contains(file.Pattern, version_file_updater.FormatStrReplacementSubstr) {
+			return nil, stacktrace.NewError("The pattern '%s' of version file '%s' doesn't contain '%s', which stands for the version", file.Pattern, file.Path, version_file_updater.FormatStrReplacementSubstr)
+		}
+	}
+	return result, nil
+}
+
+// Every non-empty line of the list is a script path relative to the root of the repo, run with the release version as its only argument
+func parsePreReleaseScriptsList(preReleaseScriptsFile []byte) *releaseConfig {
+	result := &releaseConfig{
+		Gates:              nil,
+		Hooks:              nil,
+		PreReleaseScripts:  nil,
+		MaxParallelScripts: defaultMaxParallelScripts,
+		AllowedChanges:     nil,
+		VersionFiles:       nil,
+	}
+	for _, line := range strings.Split(string(preReleaseScriptsFile), "\n") {
+		scriptFilepath := strings.TrimSpace(line)
+		if scriptFilepath == "" {
+			continue
+		}
+		command := scriptFilepath
+		// Without a '/', the script would be looked up in the PATH rather than in the repo
+		if !strings.Contains(command, "/") {
+			command = "./" + command
+		}
+		result.PreReleaseScripts = append(result.PreReleaseScripts, &scriptStep{
+			Name:  scriptFilepath,
+			Needs: nil,
+			scriptCommand: scriptCommand{
+				Command:          command,
+				Args:             nil,
+				WorkingDirectory: "",
+				Env:              nil,
+				Timeout:          0,
+			},
+			Retries:         0,
+			ContinueOnError: false,
+			When:            nil,
+			Check:           false,
+			Outputs:         nil,
+		})
+	}
+	return result
+}
+
+func (step *scriptStep) shouldRun(release *releaseInfo) bool {
+	if step.When == nil || len(step.When.Bump) == 0 {
+		return true
+	}
+	for _, conditionBumpType := range step.When.Bump {
+		if conditionBumpType == release.bumpType {
+			return true
+		}
+	}
+	return false
+}
+
+func (step *scriptStep) areNeedsFinished(finishedStepNames map[string]bool) bool {
+	for _, neededStepName := range step.Needs {
+		if !finishedStepNames[neededStepName] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the name of the first needed step that failed, or empty if none did
+func (step *scriptStep) getFailedNeed(failedStepNames map[string]bool) string {
+	for _, neededStepName := range step.Needs {
+		if failedStepNames[neededStepName] {
+			return neededStepName
+		}
+	}
+	return ""
+}
+
+func (step *scriptStep) getArgs(release *releaseInfo) []string {
+	if step.Args == nil {
+		return []string{release.version.String()}
+	}
+	var result []string
+	for _, arg := range step.Args {
+		result = append(result, strings.ReplaceAll(arg, releaseVersionArgPlaceholder, release.version.String()))
+	}
+	return result
+}
+
+// Returns the env vars to add to the environment kudet runs in for the step; the step's own env vars win over the release's
+func (step *scriptStep) getEnv(release *releaseInfo) []string {
+	return step.getCommandEnv(release.getEnv())
+}
+
+// Commands without a '/' are looked up in the PATH, so they're returned as is
+func (command *scriptCommand) getCommandPath(repoDirpath string) string {
+	if strings.Contains(command.Command, "/") && !path.IsAbs(command.Command) {
+		return path.Join(repoDirpath, command.Command)
+	}
+	return command.Command
+}
+
+func (command *scriptCommand) getWorkingDirpath(repoDirpath string) string {
+	if command.WorkingDirectory == "" {
+		return repoDirpath
+	}
+	return path.Join(repoDirpath, command.WorkingDirectory)
+}
+
+// Returns the base env vars followed by the command's own, which therefore win
+func (command *scriptCommand) getCommandEnv(baseEnv []string) []string {
+	var commandEnv []string
+	for name, value := range command.Env {
+		commandEnv = append(commandEnv, fmt.Sprintf("%s=%s", name, value))
+	}
+	// Sorted so the environment doesn't depend on map order
+	sort.Strings(commandEnv)
+	return append(append([]string{}, baseEnv...), commandEnv...)
+}
+
+// Every command step must have a command, and its name defaults to the command
+func validateCommandSteps(steps []*commandStep, configKey string) error {
+	for idx, step := range steps {
+		if step == nil || strings.TrimSpace(step.Command) == "" {
+			return stacktrace.NewError("Step #%d of '%s' has no command", idx+1, configKey)
+		}
+		if step.Name == "" {
+			step.Name = step.Command
+		}
+		if step.Timeout < 0 {
+			return stacktrace.NewError("Step '%s' of '%s' has negative timeout '%s'", step.Name, configKey, step.Timeout)
+		}
+	}
+	return nil
+}
+
+// Every needed step must be exactly one other step, and steps can't need each other in a cycle
+func validateStepDependencies(steps []*scriptStep) error {
+	stepsByName := map[string][]*scriptStep{}
+	for _, step := range steps {
+		stepsByName[step.Name] = append(stepsByName[step.Name], step)
+	}
+	for _, step := range steps {
+		for _, neededStepName := range step.Needs {
+			if len(stepsByName[neededStepName]) == 0 {
+				return stacktrace.NewError("Step '%s' needs step '%s', which doesn't exist", step.Name, neededStepName)
+			}
+			if len(stepsByName[neededStepName]) > 1 {
+				return stacktrace.NewError("Step '%s' needs step '%s', but there are several steps with that name", step.Name, neededStepName)
+			}
+			if neededStepName == step.Name {
+				return stacktrace.NewError("Step '%s' needs itself", step.Name)
+			}
+		}
+	}
+
+	// Depth-first search, where a step that's visited again while it's still being visited closes a cycle
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	visitStates := map[string]int{}
+	var visit func(step *scriptStep, stepNamesPath []string) error
+	visit = func(step *scriptStep, stepNamesPath []string) error {
+		switch visitStates[step.Name] {
+		case visiting:
+			return stacktrace.NewError("Steps need each other in a cycle: %s", strings.Join(append(stepNamesPath, step.Name), " -> "))
+		case visited:
+			return nil
+		}
+		visitStates[step.Name] = visiting
+		for _, neededStepName := range step.Needs {
+			if err := visit(stepsByName[neededStepName][0], append(stepNamesPath, step.Name)); err != nil {
+				return err
+			}
+		}
+		visitStates[step.Name] = visited
+		return nil
+	}
+	for _, step := range steps {
+		if err := visit(step, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Steps that may run at the same time because neither needs the other, directly or through other steps, must list their outputs, and those can't overlap
+// Otherwise a file both of them write would end up with the content of whichever finished last
+func validateStepOutputs(steps []*scriptStep, maxParallelScripts int) error {
+	if maxParallelScripts <= 1 {
+		return nil
+	}
+	// Needed steps are validated to be unique, so this finds the right one for every need
+	stepsByName := map[string]*scriptStep{}
+	for _, step := range steps {
+		stepsByName[step.Name] = step
+	}
+	allNeededSteps := map[*scriptStep]map[*scriptStep]bool{}
+	var getAllNeededSteps func(step *scriptStep) map[*scriptStep]bool
+	getAllNeededSteps = func(step *scriptStep) map[*scriptStep]bool {
+		if result, found := allNeededSteps[step]; found {
+			return result
+		}
+		result := map[*scriptStep]bool{}
+		for _, neededStepName := range step.Needs {
+			neededStep := stepsByName[neededStepName]
+			result[neededStep] = true
+			for indirectlyNeededStep := range getAllNeededSteps(neededStep) {
+				result[indirectlyNeededStep] = true
+			}
+		}
+		allNeededSteps[step] = result
+		return result
+	}
+
+	for idx, step := range steps {
+		for _, otherStep := range steps[idx+1:] {
+			if getAllNeededSteps(step)[otherStep] || getAllNeededSteps(otherStep)[step] {
+				continue
+			}
+			for _, concurrentStep := range []*scriptStep{step, otherStep} {
+				if concurrentStep.Outputs == nil {
+					return stacktrace.NewError("Steps '%s' and '%s' may run at the same time, so both must list the files they write under '%s', but '%s' doesn't; use an empty list for a step that writes nothing", step.Name, otherStep.Name, releaseConfigStepOutputsKey, concurrentStep.Name)
+				}
+			}
+			for _, output := range step.Outputs {
+				for _, otherOutput := range otherStep.Outputs {
+					if path_globs.Overlap(output, otherOutput) {
+						return stacktrace.NewError("Steps '%s' and '%s' may run at the same time, but both write files matching '%s' and '%s'; make one of them need the other", step.Name, otherStep.Name, output, otherOutput)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isValidBumpType(candidate bumpType) bool {
+	for _, validBumpType := range allBumpTypes {
+		if candidate == validBumpType {
+			return true
+		}
+	}
+	return false
+}
+
+func getAllBumpTypesStr() string {
+	var bumpTypeStrs []string
+	for _, validBumpType := range allBumpTypes {
+		bumpTypeStrs = append(bumpTypeStrs, string(validBumpType))
+	}
+	return strings.Join(bumpTypeStrs, ", ")
+}
@@ -0,0 +1,195 @@
+package release
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleaseConfig(t *testing.T) {
+	releaseConfig, err := parseReleaseConfig([]byte(`gates:
+  - command: go
+    args: [test, ./...]
+  - name: Smoke test
+    command: scripts/smoke-test.sh
+    timeout: 10m
+hooks:
+  post-push:
+    - command: scripts/notify.sh
+  on-failure:
+    - name: Page the releaser
+      command: curl
+      args: [-X, POST, https://example.com/page]
+pre-release-scripts:
+  - command: scripts/update-versions.sh
+  - name: Regenerate docs
+    command: make
+    args: [docs, "VERSION={version}"]
+    working-directory: docs
+    env:
+      CI: "true"
+    timeout: 5m
+    retries: 2
+    continue-on-error: true
+    when:
+      bump: [major, minor]
+    outputs: [docs/]
+allowed-changes:
+  - package.json
+  - docs/
+version-files:
+  - path: ./package.json
+    pattern: '"version": "%s"'
+`))
+	require.NoError(t, err)
+	require.Len(t, releaseConfig.PreReleaseScripts, 2)
+	require.Equal(t, []string{"package.json", "docs/"}, releaseConfig.AllowedChanges)
+	require.Equal(t, []*versionFile{{Path: "package.json", Pattern: `"version": "%s"`}}, releaseConfig.VersionFiles)
+	require.Len(t, releaseConfig.Gates, 2)
+	require.Equal(t, "go", releaseConfig.Gates[0].Name)
+	require.Equal(t, []string{"test", "./..."}, releaseConfig.Gates[0].Args)
+	require.Equal(t, "Smoke test", releaseConfig.Gates[1].Name)
+	require.Equal(t, 10*time.Minute, releaseConfig.Gates[1].Timeout)
+	require.Len(t, releaseConfig.Hooks, 2)
+	require.Equal(t, "scripts/notify.sh", releaseConfig.Hooks[postPushHookStage][0].Name)
+	require.Equal(t, "Page the releaser", releaseConfig.Hooks[onFailureHookStage][0].Name)
+
+	defaultStep := releaseConfig.PreReleaseScripts[0]
+	require.Equal(t, "scripts/update-versions.sh", defaultStep.Name)
+	require.Nil(t, defaultStep.Args)
+	require.Equal(t, time.Duration(0), defaultStep.Timeout)
+
+	customStep := releaseConfig.PreReleaseScripts[1]
+	require.Equal(t, &scriptStep{
+		Name:  "Regenerate docs",
+		Needs: nil,
+		scriptCommand: scriptCommand{
+			Command:          "make",
+			Args:             []string{"docs", "VERSION={version}"},
+			WorkingDirectory: "docs",
+			Env:              map[string]string{"CI": "true"},
+			Timeout:          5 * time.Minute,
+		},
+		Retries:         2,
+		ContinueOnError: true,
+		When:            &stepConditions{Bump: []bumpType{majorBumpType, minorBumpType}},
+		Check:           false,
+		Outputs:         []string{"docs/"},
+	}, customStep)
+}
+
+func TestParseReleaseConfigRejectsInvalidConfigs(t *testing.T) {
+	for name, releaseConfig := range map[string]string{
+		"unknownKey":         "pre-release-scripts:\n  - command: make\n    retry: 2\n",
+		"missingCommand":     "pre-release-scripts:\n  - name: nothing\n",
+		"negativeRetries":    "pre-release-scripts:\n  - command: make\n    retries: -1\n",
+		"badTimeout":         "pre-release-scripts:\n  - command: make\n    timeout: soon\n",
+		"badBumpType":        "pre-release-scripts:\n  - command: make\n    when:\n      bump: [huge]\n",
+		"gateWithoutCommand": "gates:\n  - name: nothing\n",
+		"gateWithRetries":    "gates:\n  - command: make\n    retries: 2\n",
+		"unknownHookStage":   "hooks:\n  post-release:\n    - command: make\n",
+		"hookWithoutCommand": "hooks:\n  pre-commit:\n    - name: nothing\n",
+		"versionFileOutside": "version-files:\n  - path: ../package.json\n    pattern: 'v%s'\n",
+		"versionFileNoVar":   "version-files:\n  - path: package.json\n    pattern: 'version'\n",
+		"versionFileTwice":   "version-files:\n  - path: a.txt\n    pattern: 'v%s'\n  - path: ./a.txt\n    pattern: 'w%s'\n",
+	} {
+		_, err := parseReleaseConfig([]byte(releaseConfig))
+		require.Error(t, err, "Release config '%s' should have been rejected", name)
+	}
+
+	releaseConfig, err := parseReleaseConfig(nil)
+	require.NoError(t, err, "An empty release config should be valid")
+	require.Empty(t, releaseConfig.PreReleaseScripts)
+	require.Nil(t, releaseConfig.AllowedChanges, "Without an allowlist, any change should be allowed")
+
+	releaseConfig, err = parseReleaseConfig([]byte("allowed-changes: []\n"))
+	require.NoError(t, err)
+	require.NotNil(t, releaseConfig.AllowedChanges, "An empty allowlist should only allow the files kudet changes itself")
+}
+
+func TestParseReleaseConfigValidatesStepDependencies(t *testing.T) {
+	releaseConfig, err := parseReleaseConfig([]byte("pre-release-scripts:\n  - command: ./a.sh\n  - command: ./b.sh\n    needs: [./a.sh]\n"))
+	require.NoError(t, err)
+	require.Equal(t, defaultMaxParallelScripts, releaseConfig.MaxParallelScripts)
+	require.Equal(t, []string{"./a.sh"}, releaseConfig.PreReleaseScripts[1].Needs)
+
+	for name, invalidReleaseConfig := range map[string]string{
+		"missingStep":         "pre-release-scripts:\n  - command: ./a.sh\n    needs: [./b.sh]\n",
+		"ambiguousStep":       "pre-release-scripts:\n  - command: ./a.sh\n  - command: ./a.sh\n  - command: ./b.sh\n    needs: [./a.sh]\n",
+		"selfDependency":      "pre-release-scripts:\n  - command: ./a.sh\n    needs: [./a.sh]\n",
+		"cycle":               "pre-release-scripts:\n  - command: ./a.sh\n    needs: [./c.sh]\n  - command: ./b.sh\n    needs: [./a.sh]\n  - command: ./c.sh\n    needs: [./b.sh]\n",
+		"negativeMaxParallel": "max-parallel-scripts: -1\n",
+	} {
+		_, err := parseReleaseConfig([]byte(invalidReleaseConfig))
+		require.Error(t, err, "Release config '%s' should have been rejected", name)
+	}
+	_, err = parseReleaseConfig([]byte("pre-release-scripts:\n  - command: ./a.sh\n    needs: [./c.sh]\n  - command: ./b.sh\n    needs: [./a.sh]\n  - command: ./c.sh\n    needs: [./b.sh]\n"))
+	require.ErrorContains(t, err, "./a.sh -> ./c.sh -> ./b.sh -> ./a.sh")
+}
+
+func TestParseReleaseConfigValidatesStepOutputs(t *testing.T) {
+	_, err := parseReleaseConfig([]byte(`max-parallel-scripts: 2
+pre-release-scripts:
+  - name: go sdk
+    command: ./generate.sh
+    outputs: [sdk/go/]
+  - name: python sdk
+    command: ./generate.sh
+    outputs: [sdk/python/, "*.toml"]
+  - name: checks
+    command: ./check.sh
+    outputs: []
+  - name: docs
+    command: ./docs.sh
+    needs: [go sdk, python sdk, checks]
+`))
+	require.NoError(t, err, "Steps writing different files, or needing the steps writing the same files, should be valid")
+
+	_, err = parseReleaseConfig([]byte(`max-parallel-scripts: 2
+pre-release-scripts:
+  - name: go sdk
+    command: ./generate.sh
+    outputs: [sdk/go/, package.json]
+  - name: python sdk
+    command: ./generate.sh
+    outputs: [sdk/python/, "*.json"]
+`))
+	require.ErrorContains(t, err, "Steps 'go sdk' and 'python sdk' may run at the same time, but both write files matching 'package.json' and '*.json'")
+
+	_, err = parseReleaseConfig([]byte(`max-parallel-scripts: 2
+pre-release-scripts:
+  - name: go sdk
+    command: ./generate.sh
+    outputs: [sdk/go/]
+  - name: python sdk
+    command: ./generate.sh
+`))
+	require.ErrorContains(t, err, "but 'python sdk' doesn't")
+
+	_, err = parseReleaseConfig([]byte(`pre-release-scripts:
+  - command: ./a.sh
+  - command: ./b.sh
+`))
+	require.NoError(t, err, "Steps that run one after another in order don't need to list their outputs")
+}
+
+func TestLoadReleaseConfigFallsBackToPreReleaseScriptsList(t *testing.T) {
+	dirpath := t.TempDir()
+	_, err := loadReleaseConfig(dirpath)
+	require.Error(t, err, "A repo with neither a release config nor a pre-release scripts list should be rejected")
+
+	require.NoError(t, os.WriteFile(path.Join(dirpath, preReleaseScriptsFilename), []byte("update.sh\n\nscripts/docs.sh\n"), 0644))
+	releaseConfig, err := loadReleaseConfig(dirpath)
+	require.NoError(t, err)
+	require.Len(t, releaseConfig.PreReleaseScripts, 2)
+	require.Equal(t, "update.sh", releaseConfig.PreReleaseScripts[0].Name)
+	require.Equal(t, "./update.sh", releaseConfig.PreReleaseScripts[0].Command)
+	require.Equal(t, "scripts/docs.sh", releaseConfig.PreReleaseScripts[1].Command)
+
+	require.NoError(t, os.WriteFile(path.Join(dirpath, releaseConfigFilename), []byte("pre-release-scripts: []\n"), 0644))
+	_, err = loadReleaseConfig(dirpath)
+	require.Error(t, err, "Having both a release config and a pre-release scripts list is ambiguous")
+}
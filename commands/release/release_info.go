@@ -0,0 +1,118 @@
+package release
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	previousVersionEnvVar      = "KUDET_PREVIOUS_VERSION"
+	versionEnvVar              = "KUDET_VERSION"
+	versionMajorEnvVar         = "KUDET_VERSION_MAJOR"
+	versionMinorEnvVar         = "KUDET_VERSION_MINOR"
+	versionPatchEnvVar         = "KUDET_VERSION_PATCH"
+	bumpTypeEnvVar             = "KUDET_BUMP_TYPE"
+	hasBreakingChangesEnvVar   = "KUDET_HAS_BREAKING_CHANGES"
+	repoRootEnvVar             = "KUDET_REPO_ROOT"
+	parentCommitShaEnvVar      = "KUDET_PARENT_SHA"
+	releaseNotesFilepathEnvVar = "KUDET_RELEASE_NOTES_FILE"
+
+	releaseNotesFilePattern = "kudet-release-notes-*.md"
+)
+
+// What the steps of a release get to know about the release being cut
+type releaseInfo struct {
+	// 0.0.0 if this is the first release; like the version and bump type, nil until the version is computed
+	previousVersion *semver.Version
+
+	version  *semver.Version
+	bumpType bumpType
+
+	// Whether the changelog section being released has breaking changes, regardless of the bump type
+	hasBreakingChanges bool
+
+	repoDirpath string
+
+	// The commit the release commit will be made on top of
+	parentCommitSha string
+
+	// A temporary file holding the changelog section being released, without its header
+	releaseNotesFilepath string
+
+	// Empty until the release commit is made
+	releaseCommitSha string
+
+	// Empty until the release tags are created
+	tags []string
+}
+
+// Writes the content of the unreleased version to a temporary file, whose path is returned for use as the release notes
+// The caller is responsible for removing the file
+func writeReleaseNotesFile(unreleasedVersion *changelog_ast.Version) (string, error) {
+	releaseNotesFile, err := os.CreateTemp("", releaseNotesFilePattern)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred creating a temporary file for the release notes")
+	}
+	defer releaseNotesFile.Close()
+
+	if _, err := releaseNotesFile.WriteString(getReleaseNotes(unreleasedVersion)); err != nil {
+		return "", stacktrace.Propagate(err, "An error occurred writing the release notes to temporary file '%s'", releaseNotesFile.Name())
+	}
+	return releaseNotesFile.Name(), nil
+}
+
+// Returns the environment variables that describe the release to the scripts run during it, as 'NAME=value' pairs
+// The variables about the version are left out until it's computed
+func (release *releaseInfo) getEnv() []string {
+	type envVar struct {
+		name  string
+		value string
+	}
+	var envVars []envVar
+	if release.version != nil {
+		envVars = append(envVars, []envVar{
+			{previousVersionEnvVar, release.previousVersion.String()},
+			{versionEnvVar, release.version.String()},
+			{versionMajorEnvVar, strconv.FormatUint(release.version.Major(), 10)},
+			{versionMinorEnvVar, strconv.FormatUint(release.version.Minor(), 10)},
+			{versionPatchEnvVar, strconv.FormatUint(release.version.Patch(), 10)},
+			{bumpTypeEnvVar, string(release.bumpType)},
+			{hasBreakingChangesEnvVar, strconv.FormatBool(release.hasBreakingChanges)},
+		}...)
+	}
+	envVars = append(envVars, []envVar{
+		{repoRootEnvVar, release.repoDirpath},
+		{parentCommitShaEnvVar, release.parentCommitSha},
+		{releaseNotesFilepathEnvVar, release.releaseNotesFilepath},
+	}...)
+	var result []string
+	for _, envVar := range envVars {
+		result = append(result, fmt.Sprintf("%s=%s", envVar.name, envVar.value))
+	}
+	return result
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getReleaseNotes(unreleasedVersion *changelog_ast.Version) string {
+	// The first line is the version header, which would only say 'TBD'
+	lines := unreleasedVersion.Lines()[1:]
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
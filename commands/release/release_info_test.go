@@ -0,0 +1,67 @@
+package release
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/changelog_ast"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseInfoGetEnv(t *testing.T) {
+	release := &releaseInfo{
+		previousVersion:      semver.MustParse("0.9.4"),
+		version:              semver.MustParse("1.0.0"),
+		bumpType:             majorBumpType,
+		hasBreakingChanges:   true,
+		repoDirpath:          "/repo",
+		parentCommitSha:      "0123456789abcdef0123456789abcdef01234567",
+		releaseNotesFilepath: "/tmp/notes.md",
+		releaseCommitSha:     "",
+		tags:                 nil,
+	}
+	require.Equal(t, []string{
+		"KUDET_PREVIOUS_VERSION=0.9.4",
+		"KUDET_VERSION=1.0.0",
+		"KUDET_VERSION_MAJOR=1",
+		"KUDET_VERSION_MINOR=0",
+		"KUDET_VERSION_PATCH=0",
+		"KUDET_BUMP_TYPE=major",
+		"KUDET_HAS_BREAKING_CHANGES=true",
+		"KUDET_REPO_ROOT=/repo",
+		"KUDET_PARENT_SHA=0123456789abcdef0123456789abcdef01234567",
+		"KUDET_RELEASE_NOTES_FILE=/tmp/notes.md",
+	}, release.getEnv())
+}
+
+func TestReleaseInfoGetEnvBeforeVersionIsComputed(t *testing.T) {
+	release := &releaseInfo{
+		previousVersion:      nil,
+		version:              nil,
+		bumpType:             "",
+		hasBreakingChanges:   false,
+		repoDirpath:          "/repo",
+		parentCommitSha:      "0123456789abcdef0123456789abcdef01234567",
+		releaseNotesFilepath: "",
+		releaseCommitSha:     "",
+		tags:                 nil,
+	}
+	require.Equal(t, []string{
+		"KUDET_REPO_ROOT=/repo",
+		"KUDET_PARENT_SHA=0123456789abcdef0123456789abcdef01234567",
+		"KUDET_RELEASE_NOTES_FILE=",
+	}, release.getEnv())
+}
+
+func TestWriteReleaseNotesFile(t *testing.T) {
+	changelog := changelog_ast.Parse([]byte("# TBD\n\n### Breaking Changes\n* A break\n\n### Fixes\n* A fix\n\n# 0.1.0\n* Old\n"), changelog_ast.KudetFormat)
+
+	releaseNotesFilepath, err := writeReleaseNotesFile(changelog.Versions[0])
+	require.NoError(t, err)
+	defer os.Remove(releaseNotesFilepath)
+
+	releaseNotes, err := os.ReadFile(releaseNotesFilepath)
+	require.NoError(t, err)
+	require.Equal(t, "### Breaking Changes\n* A break\n\n### Fixes\n* A fix\n", string(releaseNotes))
+}
@@ -0,0 +1,65 @@
+package release
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/version_file_updater"
+	"github.com/kurtosis-tech/stacktrace"
+	"github.com/sirupsen/logrus"
+	"path"
+	"strings"
+)
+
+// Checks that every version file can be updated to the release version, reporting all problems at once, without changing any of them
+func checkVersionFiles(repoDirpath string, versionFiles []*versionFile, release *releaseInfo) error {
+	if _, err := prepareVersionFileUpdates(repoDirpath, versionFiles, release); err != nil {
+		return stacktrace.Propagate(err, "The version files can't be updated to release version '%s'", release.version.String())
+	}
+	return nil
+}
+
+// Updates the version in every version file to the release version
+// Every file is checked before any of them is written, so a problem with one file leaves all of them untouched
+func updateVersionFiles(repoDirpath string, versionFiles []*versionFile, release *releaseInfo) error {
+	updates, err := prepareVersionFileUpdates(repoDirpath, versionFiles, release)
+	if err != nil {
+		return stacktrace.Propagate(err, "The version files can't be updated to release version '%s'; none of them were changed", release.version.String())
+	}
+	for idx, update := range updates {
+		logrus.Infof("Updating the version in '%s'...", versionFiles[idx].Path)
+		if err := update.Apply(); err != nil {
+			return stacktrace.Propagate(err, "An error occurred updating the version in '%s'", versionFiles[idx].Path)
+		}
+	}
+	return nil
+}
+
+// The paths of the version files, relative to the root of the repo like the changes of the worktree
+func getVersionFilepaths(versionFiles []*versionFile) []string {
+	var result []string
+	for _, file := range versionFiles {
+		result = append(result, file.Path)
+	}
+	return result
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func prepareVersionFileUpdates(repoDirpath string, versionFiles []*versionFile, release *releaseInfo) ([]*version_file_updater.VersionFileUpdate, error) {
+	var updates []*version_file_updater.VersionFileUpdate
+	var problems []string
+	for _, file := range versionFiles {
+		update, err := version_file_updater.PrepareUpdate(path.Join(repoDirpath, file.Path), file.Pattern, release.version.String())
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Version file '%s' can't be updated: %v", file.Path, err))
+			continue
+		}
+		updates = append(updates, update)
+	}
+	if len(problems) > 0 {
+		return nil, stacktrace.NewError("Found %d problem(s) with the version files:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return updates, nil
+}
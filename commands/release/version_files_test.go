@@ -0,0 +1,55 @@
+package release
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateVersionFiles(t *testing.T) {
+	dirpath := t.TempDir()
+	require.NoError(t, os.Mkdir(path.Join(dirpath, "api"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "package.json"), []byte("{\n  \"name\": \"app\",\n  \"version\": \"1.2.2\"\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "api", "version.go"), []byte("package api\n\nconst Version = \"1.2.2\"\n"), 0644))
+	versionFiles := []*versionFile{
+		{Path: "package.json", Pattern: `"version": "%s"`},
+		{Path: "api/version.go", Pattern: `const Version = "%s"`},
+	}
+	release := newTestReleaseInfo(dirpath, "1.3.0", minorBumpType)
+
+	require.NoError(t, checkVersionFiles(dirpath, versionFiles, release))
+	require.NoError(t, updateVersionFiles(dirpath, versionFiles, release))
+
+	packageJson, err := os.ReadFile(path.Join(dirpath, "package.json"))
+	require.NoError(t, err)
+	require.Equal(t, "{\n  \"name\": \"app\",\n  \"version\": \"1.3.0\"\n}\n", string(packageJson))
+	versionGo, err := os.ReadFile(path.Join(dirpath, "api", "version.go"))
+	require.NoError(t, err)
+	require.Equal(t, "package api\n\nconst Version = \"1.3.0\"\n", string(versionGo))
+	require.Equal(t, []string{"package.json", "api/version.go"}, getVersionFilepaths(versionFiles))
+}
+
+func TestUpdateVersionFilesChecksEveryFileFirst(t *testing.T) {
+	dirpath := t.TempDir()
+	originalPackageJson := "{\n  \"version\": \"1.2.2\"\n}\n"
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "package.json"), []byte(originalPackageJson), 0644))
+	require.NoError(t, os.WriteFile(path.Join(dirpath, "versions.txt"), []byte("VERSION=1.2.2\nVERSION=1.2.2\n"), 0644))
+	versionFiles := []*versionFile{
+		{Path: "package.json", Pattern: `"version": "%s"`},
+		{Path: "versions.txt", Pattern: "VERSION=%s"},
+		{Path: "missing.txt", Pattern: "VERSION=%s"},
+	}
+	release := newTestReleaseInfo(dirpath, "1.3.0", minorBumpType)
+
+	err := checkVersionFiles(dirpath, versionFiles, release)
+	require.ErrorContains(t, err, "Found 2 problem(s) with the version files")
+	require.ErrorContains(t, err, "Version file 'versions.txt' can't be updated")
+	require.ErrorContains(t, err, "Version file 'missing.txt' can't be updated")
+
+	require.Error(t, updateVersionFiles(dirpath, versionFiles, release))
+	packageJson, err := os.ReadFile(path.Join(dirpath, "package.json"))
+	require.NoError(t, err)
+	require.Equal(t, originalPackageJson, string(packageJson), "No file should be changed if any of them can't be updated")
+}
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"github.com/kurtosis-tech/kudet/commands/changelog"
 	"github.com/kurtosis-tech/kudet/commands/get-docker-tag"
+	"github.com/kurtosis-tech/kudet/commands/merge-driver"
 	"github.com/kurtosis-tech/kudet/commands/release"
 	"github.com/kurtosis-tech/kudet/commands/update-version-in-file"
 	"github.com/kurtosis-tech/stacktrace"
@@ -39,6 +41,8 @@ func init() {
 	RootCmd.AddCommand(release.ReleaseCmd)
 	RootCmd.AddCommand(getdockertag.GetDockerTagCmd)
 	RootCmd.AddCommand(updateversioninfile.UpdateVersionInFileCmd)
+	RootCmd.AddCommand(mergedriver.MergeDriverCmd)
+	RootCmd.AddCommand(changelog.ChangelogCmd)
 }
 
 // ====================================================================================================
@@ -0,0 +1,75 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	archiveHeaderTitle = "Archived versions"
+
+	kudetArchiveHeaderStr          = sectionHeaderPrefix + " " + archiveHeaderTitle
+	kudetArchiveHeaderRegexStr     = "^#\\s*[Aa]rchived [Vv]ersions\\s*$"
+	keepAChangelogArchiveHeaderStr = "## " + archiveHeaderTitle
+	// Can't be bracketed, so it never gets mistaken for a version header
+	keepAChangelogArchiveHeaderRegexStr = "^##\\s*[Aa]rchived [Vv]ersions\\s*$"
+
+	archiveLinkRegexStr = "^[*+-]\\s+\\[(.+?)\\]\\((.+?)\\)\\s*$"
+)
+
+var archiveLinkRegex = regexp.MustCompile(archiveLinkRegexStr)
+
+// ArchiveLink points to a file that old versions were moved to, to keep the changelog short
+type ArchiveLink struct {
+	// Zero for links that aren't in the document yet
+	LineNumber int
+
+	Label string
+
+	// Relative to the directory of the changelog
+	Target string
+}
+
+// GetArchiveLinks returns the links listed under the archive header, in document order
+func (changelog *Changelog) GetArchiveLinks() []*ArchiveLink {
+	archiveLineNumber := len(changelog.Preamble) + 1
+	for _, version := range changelog.Versions {
+		archiveLineNumber += len(version.Lines())
+	}
+
+	var result []*ArchiveLink
+	for lineIdx, line := range changelog.Archive {
+		if matches := archiveLinkRegex.FindStringSubmatch(line); matches != nil {
+			result = append(result, &ArchiveLink{
+				LineNumber: archiveLineNumber + lineIdx,
+				Label:      matches[1],
+				Target:     matches[2],
+			})
+		}
+	}
+	return result
+}
+
+// SetArchiveLinks replaces whatever is under the archive header with a list of the given links, adding the header if needed
+// If there are no links, the archive header is removed altogether
+func (changelog *Changelog) SetArchiveLinks(links []*ArchiveLink) {
+	if len(links) == 0 {
+		changelog.Archive = nil
+		return
+	}
+	// The archive header is separated from the last version by a blank line, which is owned by the version
+	if len(changelog.Versions) > 0 {
+		lastVersion := changelog.Versions[len(changelog.Versions)-1]
+		lastSection := lastVersion.Sections[len(lastVersion.Sections)-1]
+		if len(lastSection.Lines) == 0 || !emptyLineRegex.MatchString(lastSection.Lines[len(lastSection.Lines)-1]) {
+			lastSection.Lines = append(lastSection.Lines, "")
+		}
+	}
+
+	formatGrammar := getGrammar(changelog.Format)
+	archive := []string{formatGrammar.archiveHeader, ""}
+	for _, link := range links {
+		archive = append(archive, fmt.Sprintf("%s [%s](%s)", formatGrammar.bullet, link.Label, link.Target))
+	}
+	changelog.Archive = append(archive, "")
+}
@@ -0,0 +1,72 @@
+package changelog_ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchive(t *testing.T) {
+	changelogStr := `# TBD
+* Something
+
+# 1.0.0
+### Fixes
+* A fix
+
+# Archived versions
+
+* [0.x](changelog/0.x.md)
+`
+	changelog := Parse([]byte(changelogStr), KudetFormat)
+	require.Equal(t, changelogStr, string(changelog.Bytes()))
+	require.Len(t, changelog.Versions, 2)
+	require.Equal(t, []string{"* A fix", ""}, changelog.GetVersion("1.0.0").GetSection("Fixes").Lines)
+
+	links := changelog.GetArchiveLinks()
+	require.Len(t, links, 1)
+	require.Equal(t, &ArchiveLink{LineNumber: 10, Label: "0.x", Target: "changelog/0.x.md"}, links[0])
+
+	require.NoError(t, changelog.ReleaseUnreleasedVersion("1.0.1", ""))
+	require.Contains(t, string(changelog.Bytes()), "# 1.0.1\n* Something\n\n# 1.0.0")
+	require.Equal(t, "# Archived versions", changelog.Archive[0])
+}
+
+func TestParseArchive_KeepAChangelog(t *testing.T) {
+	changelogStr := `# Changelog
+
+## [Unreleased]
+
+## [1.0.0] - 2026-01-01
+- A change
+
+## Archived versions
+
+- [0.x](changelog/0.x.md)
+
+[Unreleased]: https://github.com/owner/repo/compare/1.0.0...HEAD
+[1.0.0]: https://github.com/owner/repo/compare/0.9.0...1.0.0
+`
+	changelog := Parse([]byte(changelogStr), KeepAChangelogFormat)
+	require.Equal(t, changelogStr, string(changelog.Bytes()))
+	require.Len(t, changelog.GetArchiveLinks(), 1)
+	require.Len(t, changelog.Footer, 3)
+
+	require.NoError(t, changelog.ReleaseUnreleasedVersion("1.0.1", "2026-02-01"))
+	require.Contains(t, string(changelog.Bytes()), "[1.0.1]: https://github.com/owner/repo/compare/1.0.0...1.0.1")
+}
+
+func TestSetArchiveLinks(t *testing.T) {
+	changelog := Parse([]byte("# TBD\n* Something\n\n# 1.0.0\n* A fix"), KudetFormat)
+	changelog.SetArchiveLinks([]*ArchiveLink{
+		{LineNumber: 0, Label: "1.x", Target: "changelog/1.x.md"},
+		{LineNumber: 0, Label: "0.x", Target: "changelog/0.x.md"},
+	})
+	require.Equal(t, "# TBD\n* Something\n\n# 1.0.0\n* A fix\n\n# Archived versions\n\n* [1.x](changelog/1.x.md)\n* [0.x](changelog/0.x.md)\n", string(changelog.Bytes()))
+
+	changelog.Canonicalize()
+	require.Equal(t, "# TBD\n\n* Something\n\n# 1.0.0\n\n* A fix\n\n# Archived versions\n\n* [1.x](changelog/1.x.md)\n* [0.x](changelog/0.x.md)\n", string(changelog.Bytes()))
+
+	changelog.SetArchiveLinks(nil)
+	require.Empty(t, changelog.GetArchiveLinks())
+}
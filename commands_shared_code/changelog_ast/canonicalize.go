@@ -0,0 +1,130 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	trailingWhitespaceChars = " \t\r"
+	codeFenceMarker         = "```"
+)
+
+var anyLevelEntryRegex = regexp.MustCompile("^(\\s*)[*+-]\\s+")
+
+// Canonicalize rewrites the changelog in the canonical form of its format:
+//   - version and section headers are respelled, e.g. '#TBD' becomes '# TBD' and '## Fixes' becomes '### Fixes'
+//   - every entry uses the format's bullet character
+//   - trailing whitespace is removed, and there's exactly one blank line between headers and blocks of content
+//   - the archive header, if any, is respelled and followed by the links to archived versions
+//   - the document ends with a single newline
+//
+// Lines inside fenced code blocks are left untouched, and canonicalizing a canonical changelog doesn't change it.
+func (changelog *Changelog) Canonicalize() {
+	formatGrammar := getGrammar(changelog.Format)
+
+	changelog.Preamble = canonicalizeLines(changelog.Preamble, formatGrammar.bullet)
+	if len(changelog.Preamble) > 0 && len(changelog.Versions) > 0 {
+		changelog.Preamble = append(changelog.Preamble, "")
+	}
+
+	for _, version := range changelog.Versions {
+		canonicalizeVersion(formatGrammar, version)
+		// Every version ends with the blank line that separates it from whatever comes next, which for the last version is the end of the file
+		lastSection := version.Sections[len(version.Sections)-1]
+		lastSection.Lines = append(lastSection.Lines, "")
+	}
+
+	if len(changelog.Archive) > 0 {
+		archiveLines := canonicalizeLines(changelog.Archive[1:], formatGrammar.bullet)
+		changelog.Archive = []string{formatGrammar.archiveHeader, ""}
+		if len(archiveLines) > 0 {
+			changelog.Archive = append(changelog.Archive, archiveLines...)
+			changelog.Archive = append(changelog.Archive, "")
+		}
+	}
+
+	footerLinks := canonicalizeLines(changelog.Footer, formatGrammar.bullet)
+	changelog.Footer = nil
+	if len(footerLinks) > 0 {
+		var nonEmptyFooterLinks []string
+		for _, line := range footerLinks {
+			if line != "" {
+				nonEmptyFooterLinks = append(nonEmptyFooterLinks, line)
+			}
+		}
+		changelog.Footer = append(nonEmptyFooterLinks, "")
+		if len(changelog.Versions) == 0 {
+			changelog.Preamble = append(changelog.Preamble, "")
+		}
+	}
+
+	if len(changelog.Versions) == 0 && len(changelog.Footer) == 0 && len(changelog.Preamble) > 0 {
+		changelog.Preamble = append(changelog.Preamble, "")
+	}
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func canonicalizeVersion(formatGrammar *grammar, version *Version) {
+	if version.IsUnreleased() {
+		version.Header = formatGrammar.unreleasedHeader
+	} else {
+		version.Header = formatGrammar.renderVersionHeader(version.Name, version.Date)
+	}
+
+	var canonicalSections []*Section
+	for _, section := range version.Sections {
+		section.Lines = canonicalizeLines(section.Lines, formatGrammar.bullet)
+		if !section.IsUntitled() {
+			section.Header = fmt.Sprintf("%s %s", formatGrammar.sectionHeaderPrefix, section.Title)
+		}
+		canonicalSections = append(canonicalSections, section)
+	}
+
+	// Each block of content is preceded by a blank line, which is owned by the block before it
+	untitledSection := canonicalSections[0]
+	if len(untitledSection.Lines) > 0 {
+		untitledSection.Lines = append([]string{""}, untitledSection.Lines...)
+	}
+	for idx := 1; idx < len(canonicalSections); idx++ {
+		previousSection := canonicalSections[idx-1]
+		previousSection.Lines = append(previousSection.Lines, "")
+	}
+	version.Sections = canonicalSections
+}
+
+// Strips trailing whitespace, normalizes bullets, collapses runs of blank lines and trims leading and trailing blank lines
+func canonicalizeLines(lines []string, bullet string) []string {
+	var result []string
+	isInCodeFence := false
+	for _, line := range lines {
+		if isInCodeFence {
+			result = append(result, line)
+			if strings.HasPrefix(strings.TrimSpace(line), codeFenceMarker) {
+				isInCodeFence = false
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), codeFenceMarker) {
+			isInCodeFence = true
+		}
+
+		line = strings.TrimRight(line, trailingWhitespaceChars)
+		if line == "" && (len(result) == 0 || result[len(result)-1] == "") {
+			continue
+		}
+		if matches := anyLevelEntryRegex.FindStringSubmatch(line); matches != nil {
+			line = matches[1] + bullet + " " + line[len(matches[0]):]
+		}
+		result = append(result, line)
+	}
+	for len(result) > 0 && result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package changelog_ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizeKudetChangelog(t *testing.T) {
+	changelogStr := "\n#TBD  \n## Fixes\n- A fix\n+ Another fix\n\n\n\n#1.5.2 (2026-10-16)\n* Something\n  - nested\n### Breaking Changes\n\n* A break\n```\nkeep   \n\n\n  - this\n```\n#1.5.1"
+	expectedChangelogStr := `# TBD
+
+### Fixes
+* A fix
+* Another fix
+
+# 1.5.2 - 2026-10-16
+
+* Something
+  * nested
+
+### Breaking Changes
+* A break
+` + "```\nkeep   \n\n\n  - this\n```" + `
+
+# 1.5.1
+`
+	changelog := Parse([]byte(changelogStr), KudetFormat)
+	changelog.Canonicalize()
+	require.Equal(t, expectedChangelogStr, string(changelog.Bytes()))
+}
+
+func TestCanonicalizeIsIdempotent(t *testing.T) {
+	changelogStrs := map[Format]string{
+		KudetFormat:          "# Title   \n\n\n#TBD\n* Something\n#0.1.0\n## Fixes\n- Fix",
+		KeepAChangelogFormat: keepAChangelogStr,
+	}
+	for format, changelogStr := range changelogStrs {
+		changelog := Parse([]byte(changelogStr), format)
+		changelog.Canonicalize()
+		canonicalChangelogStr := string(changelog.Bytes())
+
+		recanonicalizedChangelog := Parse([]byte(canonicalChangelogStr), format)
+		recanonicalizedChangelog.Canonicalize()
+		require.Equal(t, canonicalChangelogStr, string(recanonicalizedChangelog.Bytes()))
+	}
+}
+
+func TestCanonicalizeKeepAChangelogLeavesCanonicalChangelogUnchanged(t *testing.T) {
+	changelogStr := `# Changelog
+
+## [Unreleased]
+
+### Added
+- A new feature
+
+## [1.0.0] - 2026-09-01
+
+### Fixed
+- A fix
+
+[Unreleased]: https://github.com/owner/repo/compare/v1.0.0...HEAD
+[1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0
+`
+	changelog := Parse([]byte(changelogStr), KeepAChangelogFormat)
+	changelog.Canonicalize()
+	require.Equal(t, changelogStr, string(changelog.Bytes()))
+}
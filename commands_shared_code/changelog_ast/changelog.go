@@ -0,0 +1,296 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"strings"
+)
+
+const (
+	// The name of the unreleased version, regardless of how the format spells its header
+	VersionToBeReleasedPlaceholderStr = "TBD"
+
+	sectionHeaderPrefix = "#"
+	lineSeparator       = "\n"
+
+	entryContinuationIndent = "  "
+)
+
+var (
+	versionToBeReleasedPlaceholderHeaderStr      = fmt.Sprintf("%s %s", sectionHeaderPrefix, VersionToBeReleasedPlaceholderStr)
+	versionToBeReleasedPlaceholderHeaderRegexStr = fmt.Sprintf("^%s\\s*%s\\s*$", sectionHeaderPrefix, VersionToBeReleasedPlaceholderStr)
+	// The release date is optional, and can be written either as '# X.Y.Z - <date>' or '# X.Y.Z (<date>)'
+	versionHeaderRegexStr                     = fmt.Sprintf("^%s\\s*([0-9]+.[0-9]+.[0-9]+)(?:\\s+-\\s+(\\S.*?)|\\s*\\((.+?)\\))?\\s*$", sectionHeaderPrefix)
+	subsectionHeaderRegexStr                  = fmt.Sprintf("^%s%s+\\s*(.*?)\\s*$", sectionHeaderPrefix, sectionHeaderPrefix)
+	breakingChangesSubheaderRegexStr          = fmt.Sprintf("^%s%s%s*\\s*[Bb]reak.*$", sectionHeaderPrefix, sectionHeaderPrefix, sectionHeaderPrefix)
+	entryRegexStr                             = "^([*+-])\\s+(.*)$"
+	versionToBeReleasedPlaceholderHeaderRegex = regexp.MustCompile(versionToBeReleasedPlaceholderHeaderRegexStr)
+	versionHeaderRegex                        = regexp.MustCompile(versionHeaderRegexStr)
+	subsectionHeaderRegex                     = regexp.MustCompile(subsectionHeaderRegexStr)
+	breakingChangesRegex                      = regexp.MustCompile(breakingChangesSubheaderRegexStr)
+	entryRegex                                = regexp.MustCompile(entryRegexStr)
+	emptyLineRegex                            = regexp.MustCompile("^\\s*$")
+)
+
+// Changelog is a lossless model of a changelog document: rendering a parsed changelog gives back the exact same bytes
+type Changelog struct {
+	Format Format
+
+	// Lines before the first version header (e.g. a title, or blank lines)
+	Preamble []string
+
+	Versions []*Version
+
+	// The archive header and the links to archived versions under it, if old versions were archived
+	Archive []string
+
+	// Lines after the last version that belong to the whole document (e.g. Keep a Changelog compare links)
+	Footer []string
+}
+
+// New returns a changelog with nothing in it but an empty unreleased version
+func New(format Format) *Changelog {
+	formatGrammar := getGrammar(format)
+	return &Changelog{
+		Format:   format,
+		Preamble: append([]string{}, formatGrammar.defaultPreamble...),
+		Versions: []*Version{
+			{
+				LineNumber: 0,
+				Header:     formatGrammar.unreleasedHeader,
+				Name:       VersionToBeReleasedPlaceholderStr,
+				Semver:     nil,
+				Date:       "",
+				Sections:   []*Section{newUntitledSection(0)},
+				format:     format,
+			},
+		},
+		Archive: nil,
+		Footer:  nil,
+	}
+}
+
+// Parse never fails; content that doesn't fit the changelog grammar is kept verbatim in whichever node it falls under
+func Parse(changelogFile []byte, format Format) *Changelog {
+	formatGrammar := getGrammar(format)
+	result := &Changelog{
+		Format:   format,
+		Preamble: nil,
+		Versions: nil,
+		Archive:  nil,
+		Footer:   nil,
+	}
+	isInArchive := false
+	var currentVersion *Version
+	var currentSection *Section
+	for lineIdx, line := range strings.Split(string(changelogFile), lineSeparator) {
+		lineNumber := lineIdx + 1
+		if isInArchive || (currentVersion != nil && formatGrammar.archiveHeaderRegex.MatchString(line)) {
+			isInArchive = true
+			result.Archive = append(result.Archive, line)
+			continue
+		}
+		if version, isVersionHeader := parseVersionHeader(formatGrammar, format, line, lineNumber); isVersionHeader {
+			currentVersion = version
+			currentSection = newUntitledSection(lineNumber)
+			currentVersion.Sections = append(currentVersion.Sections, currentSection)
+			result.Versions = append(result.Versions, currentVersion)
+			continue
+		}
+		if currentVersion == nil {
+			result.Preamble = append(result.Preamble, line)
+			continue
+		}
+		if matches := formatGrammar.sectionHeaderRegex.FindStringSubmatch(line); matches != nil {
+			currentSection = &Section{
+				LineNumber: lineNumber,
+				Header:     line,
+				Title:      matches[1],
+				Lines:      nil,
+			}
+			currentVersion.Sections = append(currentVersion.Sections, currentSection)
+			continue
+		}
+		currentSection.Lines = append(currentSection.Lines, line)
+	}
+
+	if formatGrammar.hasLinkDefinitionFooter && isInArchive {
+		result.Archive, result.Footer = splitLinkDefinitionFooter(result.Archive)
+	} else if formatGrammar.hasLinkDefinitionFooter && currentSection != nil {
+		currentSection.Lines, result.Footer = splitLinkDefinitionFooter(currentSection.Lines)
+	}
+	return result
+}
+
+func (changelog *Changelog) Bytes() []byte {
+	return []byte(strings.Join(changelog.Lines(), lineSeparator))
+}
+
+func (changelog *Changelog) Lines() []string {
+	result := append([]string{}, changelog.Preamble...)
+	for _, version := range changelog.Versions {
+		result = append(result, version.Lines()...)
+	}
+	result = append(result, changelog.Archive...)
+	return append(result, changelog.Footer...)
+}
+
+// HasContent returns true if the changelog has anything other than whitespace in it
+func (changelog *Changelog) HasContent() bool {
+	return len(changelog.Versions) > 0 || hasContent(changelog.Preamble)
+}
+
+// HasUnexpectedPreambleContent returns true if there's content before the first version header that the format doesn't allow for
+func (changelog *Changelog) HasUnexpectedPreambleContent() bool {
+	return !getGrammar(changelog.Format).isPreambleContentAllowed && hasContent(changelog.Preamble)
+}
+
+// GetVersion returns the first version with the given name (either the TBD placeholder or X.Y.Z), or nil if none exists
+func (changelog *Changelog) GetVersion(name string) *Version {
+	for _, version := range changelog.Versions {
+		if version.Name == name {
+			return version
+		}
+	}
+	return nil
+}
+
+func (changelog *Changelog) GetUnreleasedVersions() []*Version {
+	var result []*Version
+	for _, version := range changelog.Versions {
+		if version.IsUnreleased() {
+			result = append(result, version)
+		}
+	}
+	return result
+}
+
+func (changelog *Changelog) GetReleasedVersions() []*Version {
+	var result []*Version
+	for _, version := range changelog.Versions {
+		if !version.IsUnreleased() {
+			result = append(result, version)
+		}
+	}
+	return result
+}
+
+// ReleaseUnreleasedVersion turns the unreleased section at the top of the changelog into a section for the given version,
+// leaving an empty unreleased section above it for the changes of the next release. The release date is optional for formats that don't require it.
+func (changelog *Changelog) ReleaseUnreleasedVersion(releaseVersionStr string, releaseDate string) error {
+	releaseVersion, err := semver.StrictNewVersion(releaseVersionStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing release version '%s' as a semantic version", releaseVersionStr)
+	}
+	if len(changelog.Versions) == 0 || !changelog.Versions[0].IsUnreleased() {
+		return stacktrace.NewError("No unreleased version header was found as the first version header of the changelog")
+	}
+	unreleasedVersion := changelog.Versions[0]
+
+	releasedVersion := &Version{
+		LineNumber: 0,
+		Header:     getGrammar(changelog.Format).renderVersionHeader(releaseVersion.String(), releaseDate),
+		Name:       releaseVersion.String(),
+		Semver:     releaseVersion,
+		Date:       releaseDate,
+		Sections:   unreleasedVersion.Sections,
+		format:     changelog.Format,
+	}
+	emptyUntitledSection := newUntitledSection(0)
+	emptyUntitledSection.Lines = []string{""}
+	unreleasedVersion.Sections = []*Section{emptyUntitledSection}
+
+	changelog.Versions = append(
+		[]*Version{unreleasedVersion, releasedVersion},
+		changelog.Versions[1:]...,
+	)
+	if getGrammar(changelog.Format).hasLinkDefinitionFooter {
+		changelog.Footer = updateCompareLinks(changelog.Footer, releaseVersion.String())
+	}
+	return nil
+}
+
+// AppendReleasedVersion adds a released version with the given entries after all the existing versions, so history can be built oldest-last
+// Entries are the text of each bullet, without the bullet itself
+func (changelog *Changelog) AppendReleasedVersion(versionStr string, releaseDate string, entries []string) error {
+	version, err := semver.StrictNewVersion(versionStr)
+	if err != nil {
+		return stacktrace.Propagate(err, "An error occurred parsing version '%s' as a semantic version", versionStr)
+	}
+	formatGrammar := getGrammar(changelog.Format)
+
+	untitledSection := newUntitledSection(0)
+	for _, entry := range entries {
+		entryLines := strings.Split(strings.TrimSpace(entry), lineSeparator)
+		untitledSection.Lines = append(untitledSection.Lines, fmt.Sprintf("%s %s", formatGrammar.bullet, entryLines[0]))
+		for _, continuationLine := range entryLines[1:] {
+			untitledSection.Lines = append(untitledSection.Lines, entryContinuationIndent+strings.TrimSpace(continuationLine))
+		}
+	}
+
+	changelog.Versions = append(changelog.Versions, &Version{
+		LineNumber: 0,
+		Header:     formatGrammar.renderVersionHeader(version.String(), releaseDate),
+		Name:       version.String(),
+		Semver:     version,
+		Date:       releaseDate,
+		Sections:   []*Section{untitledSection},
+		format:     changelog.Format,
+	})
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func parseVersionHeader(formatGrammar *grammar, format Format, line string, lineNumber int) (*Version, bool) {
+	if formatGrammar.unreleasedHeaderRegex.MatchString(line) {
+		return &Version{
+			LineNumber: lineNumber,
+			Header:     line,
+			Name:       VersionToBeReleasedPlaceholderStr,
+			Semver:     nil,
+			Date:       "",
+			Sections:   nil,
+			format:     format,
+		}, true
+	}
+	matches := formatGrammar.versionHeaderRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, false
+	}
+	// The header regex is more lenient than strict semver (e.g. leading zeroes), so unparseable versions are kept by name only
+	versionSemver, err := semver.StrictNewVersion(matches[1])
+	if err != nil {
+		versionSemver = nil
+	}
+	date := ""
+	for _, dateMatch := range matches[2:] {
+		if dateMatch != "" {
+			date = dateMatch
+			break
+		}
+	}
+	return &Version{
+		LineNumber: lineNumber,
+		Header:     line,
+		Name:       matches[1],
+		Semver:     versionSemver,
+		Date:       date,
+		Sections:   nil,
+		format:     format,
+	}, true
+}
+
+func hasContent(lines []string) bool {
+	for _, line := range lines {
+		if !emptyLineRegex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
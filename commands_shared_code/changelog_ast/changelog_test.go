@@ -0,0 +1,218 @@
+package changelog_ast
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionToBeReplacedPlaceholderHeaderRegex(t *testing.T) {
+	validStrings := []string{"# TBD", "# TBD  ", "#TBD"}
+	invalidStrings := []string{"## TBD", "# TD "}
+
+	testRegexPattern(t, "Version to Be Replaced Placeholder Header", versionToBeReleasedPlaceholderHeaderRegexStr, validStrings, invalidStrings)
+}
+
+func TestVersionHeaderRegex(t *testing.T) {
+	validStrings := []string{"# 1.54.2", "#1.5.2", "# 1.2.3 (2026-10-16)", "# 1.2.3 - 2026-10-16", "# 1.2.3 - Oct 16, 2026 "}
+	invalidStrings := []string{"## 1.54.2", "1.5.2", "# ..", "# 1.52.", "# 1..25", "# 1.52", "# 1.2.3-1", "# 1.2.3 2026-10-16"}
+
+	testRegexPattern(t, "Version Header", versionHeaderRegexStr, validStrings, invalidStrings)
+}
+
+func TestBreakingChangesSubheaderRegex(t *testing.T) {
+	validStrings := []string{"### Breaking Changes", "### breaking changes", "### break", "## Breaking Chages", "###BreakingChanges", "### Break"}
+	invalidStrings := []string{"Breaking Changes", "### Breking Changes", " ## Break"}
+
+	testRegexPattern(t, "Breaking Changes Subheader", breakingChangesSubheaderRegexStr, validStrings, invalidStrings)
+}
+
+func TestParseIsLossless(t *testing.T) {
+	changelogs := []string{
+		"",
+		"\n\n",
+		"# Changelog\nSome intro\n",
+		`
+# TBD
+* Something
+### Fixes
+* A fix
+  continued on the next line
+
+# 0.1.1
+### Breaking Changes
+* A break
+# 0.1.0
+Some unknown content
+# Not a version header
+`,
+	}
+	for _, changelogStr := range changelogs {
+		require.Equal(t, changelogStr, string(Parse([]byte(changelogStr), KudetFormat).Bytes()))
+	}
+}
+
+func TestParseBuildsVersionsAndSections(t *testing.T) {
+	changelogStr := `# My changelog
+
+#TBD
+* Something
+
+### Breaking Changes
+* A break
+
+# 0.1.0
+### Fixes
+* A fix
+`
+	changelog := Parse([]byte(changelogStr), KudetFormat)
+	require.Equal(t, []string{"# My changelog", ""}, changelog.Preamble)
+	require.Len(t, changelog.Versions, 2)
+
+	unreleasedVersion := changelog.Versions[0]
+	require.True(t, unreleasedVersion.IsUnreleased())
+	require.Equal(t, 3, unreleasedVersion.LineNumber)
+	require.True(t, unreleasedVersion.HasBreakingChanges())
+	require.Len(t, unreleasedVersion.Sections, 2)
+	require.True(t, unreleasedVersion.Sections[0].IsUntitled())
+	require.Equal(t, "Breaking Changes", unreleasedVersion.Sections[1].Title)
+	require.Equal(t, 6, unreleasedVersion.Sections[1].LineNumber)
+
+	releasedVersion := changelog.GetVersion("0.1.0")
+	require.NotNil(t, releasedVersion)
+	require.Equal(t, "0.1.0", releasedVersion.Semver.String())
+	require.False(t, releasedVersion.HasBreakingChanges())
+	require.NotNil(t, releasedVersion.GetSection("fixes"))
+}
+
+func TestEntries(t *testing.T) {
+	section := &Section{
+		LineNumber: 1,
+		Header:     "### Fixes",
+		Title:      "Fixes",
+		Lines: []string{
+			"* First",
+			"  continued",
+			"  - nested",
+			"",
+			"- Second",
+			"Not an entry",
+			"+ Third",
+		},
+	}
+	entries := section.Entries()
+	require.Len(t, entries, 3)
+	require.Equal(t, Entry{LineIdx: 0, NumLines: 3, Bullet: "*", Text: "First"}, *entries[0])
+	require.Equal(t, Entry{LineIdx: 4, NumLines: 1, Bullet: "-", Text: "Second"}, *entries[1])
+	require.Equal(t, Entry{LineIdx: 6, NumLines: 1, Bullet: "+", Text: "Third"}, *entries[2])
+	require.Equal(t, 6, section.GetLineNumber(entries[1].LineIdx))
+	require.Equal(t, "First\n  continued\n  - nested", section.GetEntryText(entries[0]))
+}
+
+func TestReleaseUnreleasedVersion(t *testing.T) {
+	changelogStr := `
+# TBD
+### Fixes
+* A fix
+
+# 0.1.0
+* Something
+`
+	expectedChangelogStr := `
+# TBD
+
+# 0.2.0
+### Fixes
+* A fix
+
+# 0.1.0
+* Something
+`
+	changelog := Parse([]byte(changelogStr), KudetFormat)
+	require.NoError(t, changelog.ReleaseUnreleasedVersion("0.2.0", ""))
+	require.Equal(t, expectedChangelogStr, string(changelog.Bytes()))
+}
+
+func TestParseVersionHeaderDates(t *testing.T) {
+	changelog := Parse([]byte("# TBD\n# 1.2.3 (2026-10-16)\n# 1.2.2 - 2026-10-01\n# 1.2.1\n"), KudetFormat)
+	require.Len(t, changelog.Versions, 4)
+	require.Equal(t, "1.2.3", changelog.Versions[1].Name)
+	require.Equal(t, "2026-10-16", changelog.Versions[1].Date)
+	require.Equal(t, "1.2.2", changelog.Versions[2].Name)
+	require.Equal(t, "2026-10-01", changelog.Versions[2].Date)
+	require.Equal(t, "", changelog.Versions[3].Date)
+}
+
+func TestReleaseUnreleasedVersionWithDate(t *testing.T) {
+	changelog := Parse([]byte("# TBD\n* Something\n\n# 0.1.0 (2026-10-01)\n* Something else\n"), KudetFormat)
+	require.NoError(t, changelog.ReleaseUnreleasedVersion("0.1.1", "2026-10-16"))
+	require.Equal(t, "# TBD\n\n# 0.1.1 - 2026-10-16\n* Something\n\n# 0.1.0 (2026-10-01)\n* Something else\n", string(changelog.Bytes()))
+}
+
+func TestReleaseUnreleasedVersionRequiresTBDFirst(t *testing.T) {
+	changelog := Parse([]byte("# 0.1.0\n* Something\n# TBD\n"), KudetFormat)
+	require.Error(t, changelog.ReleaseUnreleasedVersion("0.2.0", ""))
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func testRegexPattern(t *testing.T, regexPatternName string, regexPatternStr string, validStrings []string, invalidStrings []string) {
+	regexPattern := regexp.MustCompile(regexPatternStr)
+
+	for _, str := range validStrings {
+		patternDetected := regexPattern.Match([]byte(str))
+		require.True(t, patternDetected, "%s Pattern was not detected in this string when it should have been: '%s'.", regexPatternName, str)
+	}
+
+	for _, str := range invalidStrings {
+		patternDetected := regexPattern.Match([]byte(str))
+		require.False(t, patternDetected, "%s Pattern was detected in this string when it should not have been: '%s'.", regexPatternName, str)
+	}
+}
+
+func TestNewAndAppendReleasedVersion(t *testing.T) {
+	changelog := New(KudetFormat)
+	require.NoError(t, changelog.AppendReleasedVersion("1.1.0", "", []string{"Newest", "Multi\nline"}))
+	require.NoError(t, changelog.AppendReleasedVersion("1.0.0", "2026-01-01", []string{"Oldest"}))
+	require.Error(t, changelog.AppendReleasedVersion("not-a-version", "", nil))
+	changelog.Canonicalize()
+
+	require.Equal(t, "# TBD\n\n# 1.1.0\n\n* Newest\n* Multi\n  line\n\n# 1.0.0 - 2026-01-01\n\n* Oldest\n", string(changelog.Bytes()))
+	reparsed := Parse(changelog.Bytes(), KudetFormat)
+	require.Len(t, reparsed.GetReleasedVersions(), 2)
+	require.Equal(t, "2026-01-01", reparsed.GetVersion("1.0.0").Date)
+}
+
+func TestSetContributors(t *testing.T) {
+	changelog := Parse([]byte("# TBD\n\n# 1.0.0\n### Fixes\n* A fix\n\n# 0.1.0\n* Initial\n"), KudetFormat)
+	version := changelog.GetVersion("1.0.0")
+
+	version.SetContributors([]string{"Ada Lovelace", "Grace Hopper"})
+	expected := "# TBD\n\n# 1.0.0\n### Fixes\n* A fix\n\n### Contributors\n* Ada Lovelace\n* Grace Hopper\n\n# 0.1.0\n* Initial\n"
+	require.Equal(t, expected, string(changelog.Bytes()))
+	require.Equal(t, PatchBump, version.GetBumpLevel())
+
+	// Setting the contributors again replaces them rather than adding another section
+	reparsedChangelog := Parse([]byte(expected), KudetFormat)
+	reparsedChangelog.GetVersion("1.0.0").SetContributors([]string{"Ada Lovelace", "Grace Hopper"})
+	require.Equal(t, expected, string(reparsedChangelog.Bytes()))
+
+	reparsedChangelog.GetVersion("1.0.0").SetContributors(nil)
+	require.Equal(t, "# TBD\n\n# 1.0.0\n### Fixes\n* A fix\n\n# 0.1.0\n* Initial\n", string(reparsedChangelog.Bytes()))
+}
+
+func TestAnnotateEntry(t *testing.T) {
+	changelog := Parse([]byte("# 1.0.0\n* Single line \n* Multi\n  line\n"), KudetFormat)
+	section := changelog.Versions[0].Sections[0]
+	for _, entry := range section.Entries() {
+		section.AnnotateEntry(entry, "(abc1234)")
+	}
+	for _, entry := range section.Entries() {
+		section.AnnotateEntry(entry, "(abc1234)")
+	}
+	require.Equal(t, "# 1.0.0\n* Single line (abc1234)\n* Multi\n  line (abc1234)\n", string(changelog.Bytes()))
+}
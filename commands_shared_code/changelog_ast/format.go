@@ -0,0 +1,231 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Format string
+
+const (
+	// The format kudet has always used: '# TBD' and '# X.Y.Z' version headers, with '##' or deeper category subheaders
+	KudetFormat Format = "kudet"
+
+	// https://keepachangelog.com: '## [Unreleased]' and '## [X.Y.Z] - YYYY-MM-DD' version headers, '###' category subheaders and compare links at the bottom
+	KeepAChangelogFormat Format = "keepachangelog"
+)
+
+type BumpLevel int
+
+const (
+	PatchBump BumpLevel = iota
+	// Kudet bumps the minor version for breaking changes; the major version is only ever bumped on request
+	MinorBump
+)
+
+const (
+	keepAChangelogUnreleasedLinkLabel = "unreleased"
+	keepAChangelogHeadRef             = "HEAD"
+)
+
+var (
+	keepAChangelogUnreleasedHeaderRegexStr = "^##\\s*\\[?[Uu]nreleased\\]?\\s*$"
+	keepAChangelogVersionHeaderRegexStr    = "^##\\s*\\[?v?([0-9]+\\.[0-9]+\\.[0-9]+)\\]?(?:\\s*-\\s*(\\S.*?))?\\s*$"
+	keepAChangelogSectionHeaderRegexStr    = "^###+\\s*(.*?)\\s*$"
+	linkDefinitionRegexStr                 = "^\\s*\\[([^\\]]+)\\]:\\s*(\\S+)\\s*$"
+	compareLinkRegexStr                    = "^(.*/compare/)(\\S+?)\\.\\.\\.(\\S+)$"
+	tagVersionRegexStr                     = "^(.*?)[0-9]+\\.[0-9]+\\.[0-9]+$"
+	linkDefinitionRegex                    = regexp.MustCompile(linkDefinitionRegexStr)
+	compareLinkRegex                       = regexp.MustCompile(compareLinkRegexStr)
+	tagVersionRegex                        = regexp.MustCompile(tagVersionRegexStr)
+
+	keepAChangelogDefaultPreamble = []string{
+		"# Changelog",
+		"",
+		"All notable changes to this project will be documented in this file.",
+	}
+
+	grammars = map[Format]*grammar{
+		KudetFormat: {
+			unreleasedHeaderRegex:    versionToBeReleasedPlaceholderHeaderRegex,
+			versionHeaderRegex:       versionHeaderRegex,
+			sectionHeaderRegex:       subsectionHeaderRegex,
+			archiveHeaderRegex:       regexp.MustCompile(kudetArchiveHeaderRegexStr),
+			isPreambleContentAllowed: false,
+			defaultPreamble:          nil,
+			hasLinkDefinitionFooter:  false,
+			isReleaseDateRequired:    false,
+			knownCategories:          []string{"Features", "Changes", "Fixes", "Removals"},
+			unreleasedHeader:         versionToBeReleasedPlaceholderHeaderStr,
+			archiveHeader:            kudetArchiveHeaderStr,
+			sectionHeaderPrefix:      "###",
+			bullet:                   "*",
+			categoryBumpLevels:       map[string]BumpLevel{},
+			renderVersionHeader: func(version string, date string) string {
+				if date == "" {
+					return fmt.Sprintf("%s %s", sectionHeaderPrefix, version)
+				}
+				return fmt.Sprintf("%s %s - %s", sectionHeaderPrefix, version, date)
+			},
+		},
+		KeepAChangelogFormat: {
+			unreleasedHeaderRegex:    regexp.MustCompile(keepAChangelogUnreleasedHeaderRegexStr),
+			versionHeaderRegex:       regexp.MustCompile(keepAChangelogVersionHeaderRegexStr),
+			sectionHeaderRegex:       regexp.MustCompile(keepAChangelogSectionHeaderRegexStr),
+			archiveHeaderRegex:       regexp.MustCompile(keepAChangelogArchiveHeaderRegexStr),
+			isPreambleContentAllowed: true,
+			defaultPreamble:          keepAChangelogDefaultPreamble,
+			hasLinkDefinitionFooter:  true,
+			isReleaseDateRequired:    true,
+			knownCategories:          []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"},
+			unreleasedHeader:         "## [Unreleased]",
+			archiveHeader:            keepAChangelogArchiveHeaderStr,
+			sectionHeaderPrefix:      "###",
+			bullet:                   "-",
+			// Removing functionality breaks whoever used it, so it gets kudet's breaking changes bump, which is the minor version
+			// 'Changed' is in nearly every release and mostly isn't breaking, so breaking changes go under a breaking changes subheader instead,
+			// e.g. '### Breaking Changes', like in the kudet format
+			categoryBumpLevels: map[string]BumpLevel{
+				"added":      PatchBump,
+				"changed":    PatchBump,
+				"deprecated": PatchBump,
+				"removed":    MinorBump,
+				"fixed":      PatchBump,
+				"security":   PatchBump,
+			},
+			renderVersionHeader: func(version string, date string) string {
+				if date == "" {
+					return fmt.Sprintf("## [%s]", version)
+				}
+				return fmt.Sprintf("## [%s] - %s", version, date)
+			},
+		},
+	}
+)
+
+type grammar struct {
+	unreleasedHeaderRegex *regexp.Regexp
+
+	// The first submatch must be the version, and the first non-empty submatch after it (if any) the release date
+	versionHeaderRegex *regexp.Regexp
+
+	// The first submatch must be the title
+	sectionHeaderRegex *regexp.Regexp
+
+	// Everything from this header on links to archived versions rather than belonging to the last version
+	archiveHeaderRegex *regexp.Regexp
+
+	// Whether there can be content (e.g. a title) before the first version header
+	isPreambleContentAllowed bool
+
+	// What goes before the first version header of a brand new changelog
+	defaultPreamble []string
+
+	// Whether trailing link definitions (e.g. '[1.2.3]: https://...') belong to the document rather than the last version
+	hasLinkDefinitionFooter bool
+
+	isReleaseDateRequired bool
+
+	// The category subheaders that are conventionally used in this format, besides breaking changes subheaders
+	knownCategories []string
+
+	// Keyed by normalized section title; sections not in here fall back to breaking changes detection
+	categoryBumpLevels map[string]BumpLevel
+
+	// The canonical spelling of the unreleased version header, archive header, section header prefix and entry bullet
+	unreleasedHeader    string
+	archiveHeader       string
+	sectionHeaderPrefix string
+	bullet              string
+
+	renderVersionHeader func(version string, date string) string
+}
+
+func ParseFormat(formatStr string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(formatStr)))
+	if _, found := grammars[format]; !found {
+		return "", stacktrace.NewError("Unrecognized changelog format '%s'; valid formats are: %s", formatStr, strings.Join(GetAllFormatStrs(), ", "))
+	}
+	return format, nil
+}
+
+// IsReleaseDateRequired returns true if released version headers must carry a date in this format
+func (format Format) IsReleaseDateRequired() bool {
+	return getGrammar(format).isReleaseDateRequired
+}
+
+// GetKnownCategories returns the category subheaders that are conventionally used in this format, besides breaking changes subheaders
+func (format Format) GetKnownCategories() []string {
+	return getGrammar(format).knownCategories
+}
+
+func GetAllFormatStrs() []string {
+	var result []string
+	for format := range grammars {
+		result = append(result, string(format))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func getGrammar(format Format) *grammar {
+	result, found := grammars[format]
+	if !found {
+		return grammars[KudetFormat]
+	}
+	return result
+}
+
+// Splits the trailing link definitions (and the blank lines between them) off the given lines
+func splitLinkDefinitionFooter(lines []string) ([]string, []string) {
+	footerStartIdx := len(lines)
+	for idx := len(lines) - 1; idx >= 0; idx-- {
+		if linkDefinitionRegex.MatchString(lines[idx]) {
+			footerStartIdx = idx
+			continue
+		}
+		if !emptyLineRegex.MatchString(lines[idx]) {
+			break
+		}
+	}
+	return lines[:footerStartIdx], lines[footerStartIdx:]
+}
+
+// Points the unreleased compare link at the new release, and adds a compare link for the new release right below it.
+// Nothing happens if there's no unreleased compare link, because then we have no way of knowing what the links look like.
+func updateCompareLinks(footer []string, releaseVersion string) []string {
+	for idx, line := range footer {
+		linkDefinitionMatches := linkDefinitionRegex.FindStringSubmatch(line)
+		if linkDefinitionMatches == nil || strings.ToLower(linkDefinitionMatches[1]) != keepAChangelogUnreleasedLinkLabel {
+			continue
+		}
+		unreleasedLabel := linkDefinitionMatches[1]
+		compareLinkMatches := compareLinkRegex.FindStringSubmatch(linkDefinitionMatches[2])
+		if compareLinkMatches == nil {
+			return footer
+		}
+		compareUrlPrefix, previousRef := compareLinkMatches[1], compareLinkMatches[2]
+		tagPrefix := ""
+		if tagVersionMatches := tagVersionRegex.FindStringSubmatch(previousRef); tagVersionMatches != nil {
+			tagPrefix = tagVersionMatches[1]
+		}
+		releaseRef := tagPrefix + releaseVersion
+
+		updatedLinks := []string{
+			fmt.Sprintf("[%s]: %s%s...%s", unreleasedLabel, compareUrlPrefix, releaseRef, keepAChangelogHeadRef),
+			fmt.Sprintf("[%s]: %s%s...%s", releaseVersion, compareUrlPrefix, previousRef, releaseRef),
+		}
+		result := append([]string{}, footer[:idx]...)
+		result = append(result, updatedLinks...)
+		return append(result, footer[idx+1:]...)
+	}
+	return footer
+}
@@ -0,0 +1,109 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const keepAChangelogStr = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+### Added
+- A new feature
+
+## [1.1.0] - 2026-10-01
+### Removed
+- An old feature
+
+## [1.0.0] - 2026-09-01
+### Fixed
+- A fix
+
+[Unreleased]: https://github.com/owner/repo/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/owner/repo/compare/v1.0.0...v1.1.0
+[1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0
+`
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("KeepAChangelog")
+	require.NoError(t, err)
+	require.Equal(t, KeepAChangelogFormat, format)
+
+	_, err = ParseFormat("conventional")
+	require.Error(t, err)
+}
+
+func TestParseKeepAChangelog(t *testing.T) {
+	changelog := Parse([]byte(keepAChangelogStr), KeepAChangelogFormat)
+	require.Equal(t, keepAChangelogStr, string(changelog.Bytes()))
+	require.False(t, changelog.HasUnexpectedPreambleContent())
+
+	require.Len(t, changelog.Versions, 3)
+	require.True(t, changelog.Versions[0].IsUnreleased())
+	require.Equal(t, "1.1.0", changelog.Versions[1].Name)
+	require.Equal(t, "2026-10-01", changelog.Versions[1].Date)
+	require.Equal(t, []string{
+		"[Unreleased]: https://github.com/owner/repo/compare/v1.1.0...HEAD",
+		"[1.1.0]: https://github.com/owner/repo/compare/v1.0.0...v1.1.0",
+		"[1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0",
+		"",
+	}, changelog.Footer)
+
+	require.Equal(t, PatchBump, changelog.Versions[0].GetBumpLevel())
+	require.Equal(t, MinorBump, changelog.Versions[1].GetBumpLevel())
+	require.Equal(t, PatchBump, changelog.Versions[2].GetBumpLevel())
+}
+
+func TestKeepAChangelogCategoryBumpLevels(t *testing.T) {
+	for categoryTitle, expectedBumpLevel := range map[string]BumpLevel{
+		"Added":            PatchBump,
+		"Changed":          PatchBump,
+		"Deprecated":       PatchBump,
+		"Removed":          MinorBump,
+		"Fixed":            PatchBump,
+		"Security":         PatchBump,
+		"Breaking Changes": MinorBump,
+		"Other":            PatchBump,
+	} {
+		changelogStr := fmt.Sprintf("## [Unreleased]\n### %s\n- An entry\n", categoryTitle)
+		changelog := Parse([]byte(changelogStr), KeepAChangelogFormat)
+		require.Equal(t, expectedBumpLevel, changelog.Versions[0].GetBumpLevel(), "Category '%s' got the wrong bump level", categoryTitle)
+	}
+
+	changelog := Parse([]byte("## [Unreleased]\n### Changed\n- A change\n### Breaking Changes\n- A breaking change\n"), KeepAChangelogFormat)
+	require.Equal(t, MinorBump, changelog.Versions[0].GetBumpLevel(), "A breaking changes subheader should bump the minor version even next to patch categories")
+}
+
+func TestReleaseKeepAChangelogUpdatesCompareLinks(t *testing.T) {
+	changelog := Parse([]byte(keepAChangelogStr), KeepAChangelogFormat)
+	require.NoError(t, changelog.ReleaseUnreleasedVersion("1.1.1", "2026-10-16"))
+
+	expectedChangelogStr := `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+## [1.1.1] - 2026-10-16
+### Added
+- A new feature
+
+## [1.1.0] - 2026-10-01
+### Removed
+- An old feature
+
+## [1.0.0] - 2026-09-01
+### Fixed
+- A fix
+
+[Unreleased]: https://github.com/owner/repo/compare/v1.1.1...HEAD
+[1.1.1]: https://github.com/owner/repo/compare/v1.1.0...v1.1.1
+[1.1.0]: https://github.com/owner/repo/compare/v1.0.0...v1.1.0
+[1.0.0]: https://github.com/owner/repo/releases/tag/v1.0.0
+`
+	require.Equal(t, expectedChangelogStr, string(changelog.Bytes()))
+}
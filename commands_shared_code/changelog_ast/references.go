@@ -0,0 +1,96 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/stacktrace"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Inline code, markdown links, reference links, autolinks and bare URLs are left as they are
+	protectedSpanRegexStr = "`[^`]*`|\\[[^\\]]*\\]\\([^)]*\\)|\\[[^\\]]*\\]\\[[^\\]]*\\]|<[^>\\s]+>|https?://\\S+"
+
+	// The first submatch is what comes before the reference, which is there because Go regexes have no lookbehind
+	// A reference is either '[owner/repo]#123' or a 7 to 40 character commit SHA
+	referenceRegexStr = "(^|[^\\w/&#.-])((?:([\\w.-]+/[\\w.-]+))?#([0-9]+)|[0-9a-f]{7,40})\\b"
+
+	shaHasLetterRegexStr = "[a-f]"
+	shaHasDigitRegexStr  = "[0-9]"
+
+	issueUrlFormat  = "%s/issues/%s"
+	commitUrlFormat = "%s/commit/%s"
+)
+
+var (
+	protectedSpanRegex = regexp.MustCompile(protectedSpanRegexStr)
+	referenceRegex     = regexp.MustCompile(referenceRegexStr)
+	shaHasLetterRegex  = regexp.MustCompile(shaHasLetterRegexStr)
+	shaHasDigitRegex   = regexp.MustCompile(shaHasDigitRegexStr)
+)
+
+// LinkReferences rewrites the bare '#123', 'owner/repo#45' and commit SHA references in the version into markdown links to
+// the repo at the given web URL (e.g. 'https://github.com/owner/repo')
+// References that are already linked, or that are in code spans or fenced code blocks, are left untouched, so linking twice is the same as linking once
+func (version *Version) LinkReferences(repoUrl string) error {
+	repoUrl = strings.TrimSuffix(repoUrl, "/")
+	parsedRepoUrl, err := url.Parse(repoUrl)
+	if err != nil || parsedRepoUrl.Scheme == "" || parsedRepoUrl.Host == "" {
+		return stacktrace.NewError("Repo URL '%s' isn't an absolute URL like 'https://github.com/owner/repo'", repoUrl)
+	}
+	hostUrl := fmt.Sprintf("%s://%s", parsedRepoUrl.Scheme, parsedRepoUrl.Host)
+
+	for _, section := range version.Sections {
+		isInCodeFence := false
+		for idx, line := range section.Lines {
+			if strings.HasPrefix(strings.TrimSpace(line), codeFenceMarker) {
+				isInCodeFence = !isInCodeFence
+				continue
+			}
+			if isInCodeFence || linkDefinitionRegex.MatchString(line) {
+				continue
+			}
+			section.Lines[idx] = linkReferencesInLine(line, repoUrl, hostUrl)
+		}
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func linkReferencesInLine(line string, repoUrl string, hostUrl string) string {
+	result := &strings.Builder{}
+	unprotectedStartIdx := 0
+	for _, protectedSpan := range protectedSpanRegex.FindAllStringIndex(line, -1) {
+		result.WriteString(linkReferencesInText(line[unprotectedStartIdx:protectedSpan[0]], repoUrl, hostUrl))
+		result.WriteString(line[protectedSpan[0]:protectedSpan[1]])
+		unprotectedStartIdx = protectedSpan[1]
+	}
+	result.WriteString(linkReferencesInText(line[unprotectedStartIdx:], repoUrl, hostUrl))
+	return result.String()
+}
+
+func linkReferencesInText(text string, repoUrl string, hostUrl string) string {
+	return referenceRegex.ReplaceAllStringFunc(text, func(match string) string {
+		submatches := referenceRegex.FindStringSubmatch(match)
+		prefix, reference, otherRepo, issueNumber := submatches[1], submatches[2], submatches[3], submatches[4]
+
+		var link string
+		switch {
+		case issueNumber != "" && otherRepo != "":
+			link = fmt.Sprintf(issueUrlFormat, hostUrl+"/"+otherRepo, issueNumber)
+		case issueNumber != "":
+			link = fmt.Sprintf(issueUrlFormat, repoUrl, issueNumber)
+		// Words like 'deadbeef' or numbers like '20260101' aren't likely to be SHAs
+		case shaHasLetterRegex.MatchString(reference) && shaHasDigitRegex.MatchString(reference):
+			link = fmt.Sprintf(commitUrlFormat, repoUrl, reference)
+		default:
+			return match
+		}
+		return fmt.Sprintf("%s[%s](%s)", prefix, reference, link)
+	})
+}
@@ -0,0 +1,47 @@
+package changelog_ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const referencesTestRepoUrl = "https://github.com/kurtosis-tech/kudet"
+
+func TestLinkReferences(t *testing.T) {
+	changelog := Parse([]byte("# 1.0.0\n"+
+		"* Fix the thing (#123)\n"+
+		"* Ported from kurtosis-tech/kurtosis#45, see also abc1234 and 0123456789abcdef0123456789abcdef01234567\n"+
+		"### Fixes\n"+
+		"* Already linked [#7](https://github.com/kurtosis-tech/kudet/pull/7) and <https://example.com/#8>\n"+
+		"* Code stays as-is: `git show abc1234` and `#9`, as do https://example.com/abc1234#10 and &#123;\n"+
+		"* Not references: deadbeef, 20260101, issue#5, 1.2.3\n"+
+		"```\n"+
+		"#11 abc1234\n"+
+		"```\n"+
+		"[#12]: https://example.com\n"), KudetFormat)
+	version := changelog.Versions[0]
+	require.NoError(t, version.LinkReferences(referencesTestRepoUrl+"/"))
+
+	expected := "# 1.0.0\n" +
+		"* Fix the thing ([#123](https://github.com/kurtosis-tech/kudet/issues/123))\n" +
+		"* Ported from [kurtosis-tech/kurtosis#45](https://github.com/kurtosis-tech/kurtosis/issues/45), see also [abc1234](https://github.com/kurtosis-tech/kudet/commit/abc1234) and [0123456789abcdef0123456789abcdef01234567](https://github.com/kurtosis-tech/kudet/commit/0123456789abcdef0123456789abcdef01234567)\n" +
+		"### Fixes\n" +
+		"* Already linked [#7](https://github.com/kurtosis-tech/kudet/pull/7) and <https://example.com/#8>\n" +
+		"* Code stays as-is: `git show abc1234` and `#9`, as do https://example.com/abc1234#10 and &#123;\n" +
+		"* Not references: deadbeef, 20260101, issue#5, 1.2.3\n" +
+		"```\n" +
+		"#11 abc1234\n" +
+		"```\n" +
+		"[#12]: https://example.com\n"
+	require.Equal(t, expected, string(changelog.Bytes()))
+
+	// Linking is idempotent
+	require.NoError(t, version.LinkReferences(referencesTestRepoUrl))
+	require.Equal(t, expected, string(changelog.Bytes()))
+}
+
+func TestLinkReferences_InvalidRepoUrl(t *testing.T) {
+	changelog := Parse([]byte("# 1.0.0\n* Fix (#1)\n"), KudetFormat)
+	require.Error(t, changelog.Versions[0].LinkReferences("kurtosis-tech/kudet"))
+}
@@ -0,0 +1,136 @@
+package changelog_ast
+
+import (
+	"strings"
+)
+
+const (
+	continuationLineIndentChars = " \t"
+
+	// Credits the people who contributed to a version, rather than describing changes
+	ContributorsSectionTitle = "Contributors"
+)
+
+type Section struct {
+	// 1-indexed line number of the header in the parsed document; for the untitled section this is the line number of the version header
+	LineNumber int
+
+	// The header line verbatim, or empty for the untitled section
+	Header string
+
+	Title string
+
+	// Every line below the header, including blank lines
+	Lines []string
+}
+
+// Entry is a bullet of a section, along with any indented lines (e.g. nested bullets) that follow it
+type Entry struct {
+	// Index of the first line of the entry in the section's lines
+	LineIdx int
+
+	NumLines int
+
+	// One of '*', '-' or '+'
+	Bullet string
+
+	// Text of the first line of the entry, after the bullet
+	Text string
+}
+
+func (section *Section) IsUntitled() bool {
+	return section.Header == ""
+}
+
+func (section *Section) IsBreakingChanges() bool {
+	return breakingChangesRegex.MatchString(section.Header)
+}
+
+func (section *Section) IsContributors() bool {
+	return section.Key() == normalizeSectionTitle(ContributorsSectionTitle)
+}
+
+// Key identifies sections with the same title across documents, regardless of header formatting and casing
+func (section *Section) Key() string {
+	return normalizeSectionTitle(section.Title)
+}
+
+func (section *Section) HasContent() bool {
+	return hasContent(section.Lines)
+}
+
+// GetLineNumber returns the 1-indexed line number of the section's line at the given index in the parsed document
+func (section *Section) GetLineNumber(lineIdx int) int {
+	return section.LineNumber + 1 + lineIdx
+}
+
+func (section *Section) Entries() []*Entry {
+	var result []*Entry
+	var currentEntry *Entry
+	for lineIdx, line := range section.Lines {
+		if matches := entryRegex.FindStringSubmatch(line); matches != nil {
+			currentEntry = &Entry{
+				LineIdx:  lineIdx,
+				NumLines: 1,
+				Bullet:   matches[1],
+				Text:     matches[2],
+			}
+			result = append(result, currentEntry)
+			continue
+		}
+		isContinuationLine := currentEntry != nil &&
+			currentEntry.LineIdx+currentEntry.NumLines == lineIdx &&
+			!emptyLineRegex.MatchString(line) &&
+			strings.ContainsAny(line[:1], continuationLineIndentChars)
+		if isContinuationLine {
+			currentEntry.NumLines++
+			continue
+		}
+		currentEntry = nil
+	}
+	return result
+}
+
+// GetEntryText returns the text of the entry after the bullet, including any continuation lines
+func (section *Section) GetEntryText(entry *Entry) string {
+	lines := []string{entry.Text}
+	for _, line := range section.Lines[entry.LineIdx+1 : entry.LineIdx+entry.NumLines] {
+		lines = append(lines, strings.TrimRight(line, continuationLineIndentChars))
+	}
+	return strings.Join(lines, lineSeparator)
+}
+
+// AnnotateEntry appends the annotation to the last line of the entry, unless the entry already contains it
+func (section *Section) AnnotateEntry(entry *Entry, annotation string) {
+	if strings.Contains(section.GetEntryText(entry), annotation) {
+		return
+	}
+	lastLineIdx := entry.LineIdx + entry.NumLines - 1
+	section.Lines[lastLineIdx] = strings.TrimRight(section.Lines[lastLineIdx], continuationLineIndentChars) + " " + annotation
+}
+
+// AllLines returns the header (if any) followed by the section's lines
+func (section *Section) AllLines() []string {
+	if section.IsUntitled() {
+		return section.Lines
+	}
+	return append([]string{section.Header}, section.Lines...)
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newUntitledSection(versionLineNumber int) *Section {
+	return &Section{
+		LineNumber: versionLineNumber,
+		Header:     "",
+		Title:      "",
+		Lines:      nil,
+	}
+}
+
+func normalizeSectionTitle(title string) string {
+	return strings.ToLower(strings.TrimSpace(title))
+}
@@ -0,0 +1,122 @@
+package changelog_ast
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+)
+
+type Version struct {
+	// 1-indexed line number of the header in the parsed document, or 0 if the version was created after parsing
+	LineNumber int
+
+	// The header line, verbatim
+	Header string
+
+	// Either the TBD placeholder or the X.Y.Z version
+	Name string
+
+	// Nil for the TBD placeholder
+	Semver *semver.Version
+
+	// Release date as written in the header, or empty if the header has none
+	Date string
+
+	// The first section is always the untitled one holding the lines directly under the version header
+	Sections []*Section
+
+	format Format
+}
+
+func (version *Version) IsUnreleased() bool {
+	return version.Name == VersionToBeReleasedPlaceholderStr
+}
+
+// HasContent returns true if there's anything other than whitespace under the version header
+func (version *Version) HasContent() bool {
+	for _, section := range version.Sections {
+		if !section.IsUntitled() || hasContent(section.Lines) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBumpLevel returns the version bump the changes in this version call for, according to the format's categories
+func (version *Version) GetBumpLevel() BumpLevel {
+	categoryBumpLevels := getGrammar(version.format).categoryBumpLevels
+	result := PatchBump
+	for _, section := range version.Sections {
+		sectionBumpLevel, found := categoryBumpLevels[section.Key()]
+		if !found && section.IsBreakingChanges() {
+			sectionBumpLevel = MinorBump
+		}
+		if sectionBumpLevel > result {
+			result = sectionBumpLevel
+		}
+	}
+	return result
+}
+
+func (version *Version) HasBreakingChanges() bool {
+	for _, section := range version.Sections {
+		if section.IsBreakingChanges() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSection returns the first section with the given title, compared case-insensitively, or nil if none exists
+func (version *Version) GetSection(title string) *Section {
+	for _, section := range version.Sections {
+		if section.Key() == normalizeSectionTitle(title) {
+			return section
+		}
+	}
+	return nil
+}
+
+func (version *Version) Lines() []string {
+	result := []string{version.Header}
+	for _, section := range version.Sections {
+		result = append(result, section.AllLines()...)
+	}
+	return result
+}
+
+// SetContributors lists the given names in a contributors section at the end of the version, replacing any existing contributors section
+// If there are no names, the version won't have a contributors section
+func (version *Version) SetContributors(names []string) {
+	var sections []*Section
+	for _, section := range version.Sections {
+		if !section.IsContributors() {
+			sections = append(sections, section)
+		}
+	}
+	if len(names) == 0 {
+		version.Sections = sections
+		return
+	}
+
+	// The blank lines that separate the version from the next one move to the end of the contributors section
+	lastSection := sections[len(sections)-1]
+	numTrailingEmptyLines := 0
+	for numTrailingEmptyLines < len(lastSection.Lines) && emptyLineRegex.MatchString(lastSection.Lines[len(lastSection.Lines)-1-numTrailingEmptyLines]) {
+		numTrailingEmptyLines++
+	}
+	trailingEmptyLines := append([]string{}, lastSection.Lines[len(lastSection.Lines)-numTrailingEmptyLines:]...)
+	lastSection.Lines = append(append([]string{}, lastSection.Lines[:len(lastSection.Lines)-numTrailingEmptyLines]...), "")
+
+	formatGrammar := getGrammar(version.format)
+	contributorsSection := &Section{
+		LineNumber: 0,
+		Header:     fmt.Sprintf("%s %s", formatGrammar.sectionHeaderPrefix, ContributorsSectionTitle),
+		Title:      ContributorsSectionTitle,
+		Lines:      nil,
+	}
+	for _, name := range names {
+		contributorsSection.Lines = append(contributorsSection.Lines, fmt.Sprintf("%s %s", formatGrammar.bullet, name))
+	}
+	contributorsSection.Lines = append(contributorsSection.Lines, trailingEmptyLines...)
+	version.Sections = append(sections, contributorsSection)
+}
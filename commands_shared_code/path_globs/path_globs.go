@@ -0,0 +1,222 @@
+package path_globs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ToRegex converts a glob to a regex matching the whole path, where '**' matches any number of directories, '*' and '?' don't match '/',
+// and a glob ending in '/' matches everything under that directory
+func ToRegex(glob string) *regexp.Regexp {
+	if strings.HasSuffix(glob, "/") {
+		glob = glob + "**"
+	}
+	regexStr := &strings.Builder{}
+	regexStr.WriteString("^")
+	for idx := 0; idx < len(glob); idx++ {
+		switch {
+		case strings.HasPrefix(glob[idx:], "**/"):
+			regexStr.WriteString("(?:.*/)?")
+			idx += 2
+		case strings.HasPrefix(glob[idx:], "**"):
+			regexStr.WriteString(".*")
+			idx++
+		case glob[idx] == '*':
+			regexStr.WriteString("[^/]*")
+		case glob[idx] == '?':
+			regexStr.WriteString("[^/]")
+		default:
+			regexStr.WriteString(regexp.QuoteMeta(glob[idx : idx+1]))
+		}
+	}
+	regexStr.WriteString("$")
+	return regexp.MustCompile(regexStr.String())
+}
+
+// AllToRegexes converts every glob to a regex, in order
+func AllToRegexes(globs []string) []*regexp.Regexp {
+	result := []*regexp.Regexp{}
+	for _, glob := range globs {
+		result = append(result, ToRegex(glob))
+	}
+	return result
+}
+
+// MatchesAny returns true if the path matches any of the regexes
+func MatchesAny(filepath string, regexes []*regexp.Regexp) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(filepath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlap returns true if some path matches both globs
+func Overlap(glob string, otherGlob string) bool {
+	globNfa := newGlobNfa(glob)
+	otherGlobNfa := newGlobNfa(otherGlob)
+
+	// Every char of either glob, plus '/' and a char that's in neither, covers every way a path char can be matched
+	candidateChars := map[byte]bool{'/': true}
+	for _, char := range []byte(glob + otherGlob) {
+		candidateChars[char] = true
+	}
+	for char := byte(1); ; char++ {
+		if !candidateChars[char] {
+			candidateChars[char] = true
+			break
+		}
+	}
+
+	// Breadth-first search of the pairs of states both globs can be in after matching the same chars
+	type statePair struct {
+		state      int
+		otherState int
+	}
+	visited := map[statePair]bool{}
+	var toVisit []statePair
+	for _, state := range globNfa.getEpsilonClosure(0) {
+		for _, otherState := range otherGlobNfa.getEpsilonClosure(0) {
+			toVisit = append(toVisit, statePair{state, otherState})
+		}
+	}
+	for len(toVisit) > 0 {
+		pair := toVisit[0]
+		toVisit = toVisit[1:]
+		if visited[pair] {
+			continue
+		}
+		visited[pair] = true
+		if pair.state == globNfa.acceptingState && pair.otherState == otherGlobNfa.acceptingState {
+			return true
+		}
+		for char := range candidateChars {
+			for _, nextState := range globNfa.getNextStates(pair.state, char) {
+				for _, otherNextState := range otherGlobNfa.getNextStates(pair.otherState, char) {
+					toVisit = append(toVisit, statePair{nextState, otherNextState})
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func newGlobNfa(glob string) *globNfa {
+	if strings.HasSuffix(glob, "/") {
+		glob = glob + "**"
+	}
+	result := &globNfa{
+		transitions:        nil,
+		epsilonTransitions: nil,
+		acceptingState:     0,
+	}
+	addState := func() int {
+		result.transitions = append(result.transitions, nil)
+		result.epsilonTransitions = append(result.epsilonTransitions, nil)
+		return len(result.transitions) - 1
+	}
+	addTransition := func(fromState int, charClass globCharClass, literal byte, toState int) {
+		result.transitions[fromState] = append(result.transitions[fromState], &globTransition{
+			charClass:   charClass,
+			literal:     literal,
+			targetState: toState,
+		})
+	}
+	addEpsilonTransition := func(fromState int, toState int) {
+		result.epsilonTransitions[fromState] = append(result.epsilonTransitions[fromState], toState)
+	}
+
+	state := addState()
+	for idx := 0; idx < len(glob); idx++ {
+		nextState := addState()
+		switch {
+		case strings.HasPrefix(glob[idx:], "**/"):
+			// Either nothing, or anything ending in a '/'
+			dirsState := addState()
+			addEpsilonTransition(state, nextState)
+			addEpsilonTransition(state, dirsState)
+			addTransition(dirsState, anyGlobCharClass, 0, dirsState)
+			addTransition(dirsState, literalGlobCharClass, '/', nextState)
+			idx += 2
+		case strings.HasPrefix(glob[idx:], "**"):
+			addTransition(state, anyGlobCharClass, 0, state)
+			addEpsilonTransition(state, nextState)
+			idx++
+		case glob[idx] == '*':
+			addTransition(state, notSlashGlobCharClass, 0, state)
+			addEpsilonTransition(state, nextState)
+		case glob[idx] == '?':
+			addTransition(state, notSlashGlobCharClass, 0, nextState)
+		default:
+			addTransition(state, literalGlobCharClass, glob[idx], nextState)
+		}
+		state = nextState
+	}
+	result.acceptingState = state
+	return result
+}
+
+type globCharClass int
+
+const (
+	literalGlobCharClass globCharClass = iota
+	notSlashGlobCharClass
+	anyGlobCharClass
+)
+
+type globTransition struct {
+	charClass globCharClass
+
+	// Only used by the literal char class
+	literal byte
+
+	targetState int
+}
+
+// A nondeterministic automaton matching the same paths as a glob, whose first state is the starting one
+type globNfa struct {
+	transitions        [][]*globTransition
+	epsilonTransitions [][]int
+	acceptingState     int
+}
+
+// Returns the state and every state reachable from it without matching a char
+func (nfa *globNfa) getEpsilonClosure(state int) []int {
+	result := []int{state}
+	seenStates := map[int]bool{state: true}
+	for idx := 0; idx < len(result); idx++ {
+		for _, nextState := range nfa.epsilonTransitions[result[idx]] {
+			if !seenStates[nextState] {
+				seenStates[nextState] = true
+				result = append(result, nextState)
+			}
+		}
+	}
+	return result
+}
+
+// Returns the states reachable from the state by matching the char
+func (nfa *globNfa) getNextStates(state int, char byte) []int {
+	var result []int
+	for _, transition := range nfa.transitions[state] {
+		isMatch := false
+		switch transition.charClass {
+		case literalGlobCharClass:
+			isMatch = char == transition.literal
+		case notSlashGlobCharClass:
+			isMatch = char != '/'
+		case anyGlobCharClass:
+			isMatch = true
+		}
+		if isMatch {
+			result = append(result, nfa.getEpsilonClosure(transition.targetState)...)
+		}
+	}
+	return result
+}
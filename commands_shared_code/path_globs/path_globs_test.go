@@ -0,0 +1,40 @@
+package path_globs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToRegex(t *testing.T) {
+	require.True(t, ToRegex("**/*.md").MatchString("README.md"))
+	require.True(t, ToRegex("**/*.md").MatchString("docs/nested/file.md"))
+	require.False(t, ToRegex("*.md").MatchString("docs/file.md"))
+	require.True(t, ToRegex("docs/**").MatchString("docs/nested/file.go"))
+	require.True(t, ToRegex("docs/").MatchString("docs/file.go"))
+	require.False(t, ToRegex("docs/**").MatchString("documentation/file.go"))
+	require.True(t, ToRegex("file?.go").MatchString("file1.go"))
+	require.False(t, ToRegex("file?.go").MatchString("file/.go"))
+}
+
+func TestMatchesAny(t *testing.T) {
+	regexes := AllToRegexes([]string{"docs/**", "*.go"})
+	require.True(t, MatchesAny("docs/changelog.md", regexes))
+	require.True(t, MatchesAny("main.go", regexes))
+	require.False(t, MatchesAny("cmd/main.go", regexes))
+	require.False(t, MatchesAny("main.go", nil))
+}
+
+func TestOverlap(t *testing.T) {
+	require.True(t, Overlap("package.json", "package.json"))
+	require.True(t, Overlap("docs/", "docs/api.md"))
+	require.True(t, Overlap("**/*.md", "docs/a*"))
+	require.True(t, Overlap("sdk/*/version.go", "sdk/go/*.go"))
+	require.True(t, Overlap("**/version.go", "version.go"))
+	require.True(t, Overlap("file?.go", "*1.go"))
+	require.False(t, Overlap("sdk/go/", "sdk/python/"))
+	require.False(t, Overlap("*.md", "docs/*.md"))
+	require.False(t, Overlap("docs/*.md", "docs/*.go"))
+	require.False(t, Overlap("**/version.go", "**/version.py"))
+	require.False(t, Overlap("file?.go", "file.go"))
+}
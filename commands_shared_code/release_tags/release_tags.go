@@ -0,0 +1,99 @@
+package release_tags
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kurtosis-tech/stacktrace"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	tagsPrefix     = "refs/tags/"
+	semverRegexStr = "^[0-9]+.[0-9]+.[0-9]+$"
+)
+
+var semverRegex = regexp.MustCompile(semverRegexStr)
+
+// A tag created by a release, i.e. one named with a bare X.Y.Z version
+type ReleaseTag struct {
+	Name       string
+	Version    *semver.Version
+	CommitHash plumbing.Hash
+}
+
+// GetReleaseTags returns the release tags of the repo, oldest version first
+// Tags that aren't bare X.Y.Z versions, like the 'v'-prefixed duplicates created by releases, are left out
+func GetReleaseTags(repo *git.Repository) ([]*ReleaseTag, error) {
+	tagrefs, err := repo.Tags()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while retrieving tags for repository.")
+	}
+
+	var result []*ReleaseTag
+	err = tagrefs.ForEach(func(tagref *plumbing.Reference) error {
+		tagName := strings.ReplaceAll(tagref.Name().String(), tagsPrefix, "")
+		if !semverRegex.Match([]byte(tagName)) {
+			return nil
+		}
+		tagSemVer, err := semver.StrictNewVersion(tagName)
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred parsing '%s' tag into a semver object.", tagName)
+		}
+		// Annotated tags point to a tag object rather than to the commit
+		tagCommitHash, err := repo.ResolveRevision(plumbing.Revision(tagref.Name().String()))
+		if err != nil {
+			return stacktrace.Propagate(err, "An error occurred resolving the commit of tag '%s'", tagName)
+		}
+		result = append(result, &ReleaseTag{
+			Name:       tagName,
+			Version:    tagSemVer,
+			CommitHash: *tagCommitHash,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while iterating through tagrefs in the repository.")
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version.LessThan(result[j].Version)
+	})
+	return result, nil
+}
+
+// GetCommitsAfter returns the commits reachable from the given commit that aren't part of the given release, newest first
+// If the release tag is nil, every commit reachable from the given commit is returned
+func GetCommitsAfter(repo *git.Repository, releaseTag *ReleaseTag, commitHash plumbing.Hash) ([]*object.Commit, error) {
+	releasedCommitHashes := map[plumbing.Hash]bool{}
+	if releaseTag != nil {
+		releaseCommit, err := repo.CommitObject(releaseTag.CommitHash)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred getting commit '%s' of tag '%s'", releaseTag.CommitHash, releaseTag.Name)
+		}
+		err = object.NewCommitPreorderIter(releaseCommit, nil, nil).ForEach(func(commit *object.Commit) error {
+			releasedCommitHashes[commit.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "An error occurred iterating over the commits of tag '%s'", releaseTag.Name)
+		}
+	}
+
+	commit, err := repo.CommitObject(commitHash)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred getting commit '%s'", commitHash)
+	}
+	var result []*object.Commit
+	err = object.NewCommitPreorderIter(commit, releasedCommitHashes, nil).ForEach(func(commit *object.Commit) error {
+		result = append(result, commit)
+		return nil
+	})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred iterating over the commits of '%s'", commitHash)
+	}
+	return result, nil
+}
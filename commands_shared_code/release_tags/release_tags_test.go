@@ -0,0 +1,81 @@
+package release_tags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemverRegex(t *testing.T) {
+	validStrings := []string{"0.0.0", "1.26.11234", "0.1.11", "1.2.3"}
+	invalidStrings := []string{" 0.0.0", "1.1", ".5.6", "1.2.", "..", "0.0.0 ", "v1.2.3"}
+
+	for _, str := range validStrings {
+		require.True(t, semverRegex.MatchString(str), "Semver pattern was not detected in this string when it should have been: '%s'.", str)
+	}
+	for _, str := range invalidStrings {
+		require.False(t, semverRegex.MatchString(str), "Semver pattern was detected in this string when it should not have been: '%s'.", str)
+	}
+}
+
+func TestGetReleaseTags(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "Test", Email: "test@kurtosistech.com", When: time.Now()}
+
+	firstCommitHash, err := worktree.Commit("First", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	secondCommitHash, err := worktree.Commit("Second", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	_, err = repo.CreateTag("0.10.0", secondCommitHash, &git.CreateTagOptions{Tagger: signature, Message: "0.10.0"})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("v0.10.0", secondCommitHash, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("0.9.0", firstCommitHash, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("not-a-release", firstCommitHash, nil)
+	require.NoError(t, err)
+
+	releaseTags, err := GetReleaseTags(repo)
+	require.NoError(t, err)
+	require.Len(t, releaseTags, 2)
+	require.Equal(t, "0.9.0", releaseTags[0].Name)
+	require.Equal(t, firstCommitHash, releaseTags[0].CommitHash)
+	require.Equal(t, "0.10.0", releaseTags[1].Name)
+	require.Equal(t, secondCommitHash, releaseTags[1].CommitHash)
+}
+
+func TestGetCommitsAfter(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "Test", Email: "test@kurtosistech.com", When: time.Now()}
+
+	firstCommitHash, err := worktree.Commit("First", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	_, err = repo.CreateTag("0.1.0", firstCommitHash, nil)
+	require.NoError(t, err)
+	_, err = worktree.Commit("Second", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	thirdCommitHash, err := worktree.Commit("Third", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	releaseTags, err := GetReleaseTags(repo)
+	require.NoError(t, err)
+	commits, err := GetCommitsAfter(repo, releaseTags[0], thirdCommitHash)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "Third", commits[0].Message)
+	require.Equal(t, "Second", commits[1].Message)
+
+	commits, err = GetCommitsAfter(repo, nil, thirdCommitHash)
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+}
@@ -0,0 +1,95 @@
+package version_file_updater
+
+import (
+	"fmt"
+	"github.com/kurtosis-tech/kudet/commands_shared_code/file_line_matcher"
+	"github.com/kurtosis-tech/stacktrace"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	VersionRegexStr = "[0-9A-Za-z_./-]+"
+
+	// Stands for the version in pattern format strings
+	FormatStrReplacementSubstr = "%s"
+
+	expectedNumSearchPatternLines = 1
+)
+
+var versionRegex = regexp.MustCompile(VersionRegexStr)
+
+// An update of the version in a file that was checked, but not written yet
+type VersionFileUpdate struct {
+	filepath         string
+	fileMode         os.FileMode
+	updatedFileBytes []byte
+}
+
+// PrepareUpdate checks that exactly one line of the file matches the pattern format string, with its '%s' standing for any version,
+// and computes the content of the file with the version on that line replaced by the new version
+// Nothing is written until Apply is called, so several files can be checked before any of them is changed
+func PrepareUpdate(toUpdateFilepath string, patternFormatStr string, newVersion string) (*VersionFileUpdate, error) {
+	fileToUpdateInfo, err := os.Stat(toUpdateFilepath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, stacktrace.Propagate(err, "No file exists at '%s'", toUpdateFilepath)
+		}
+		return nil, stacktrace.Propagate(err, "An error occurred attempting to retrieve file info for file at '%s'", toUpdateFilepath)
+	}
+	if !strings.Contains(patternFormatStr, FormatStrReplacementSubstr) {
+		return nil, stacktrace.NewError("The replacement substring '%s' was not found in the provided match regex '%s' as required.", FormatStrReplacementSubstr, patternFormatStr)
+	}
+	if !versionRegex.Match([]byte(newVersion)) {
+		return nil, stacktrace.NewError("The provided version '%s' does not match the version regex '%s'", newVersion, VersionRegexStr)
+	}
+
+	fileToUpdateBytes, err := os.ReadFile(toUpdateFilepath)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred attempting to read file at '%s'", toUpdateFilepath)
+	}
+
+	searchPatternStr := fmt.Sprintf(patternFormatStr, VersionRegexStr)
+	searchPatternRegex, err := regexp.Compile(searchPatternStr)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred creating regex pattern of '%s'", searchPatternStr)
+	}
+
+	replaceValue := fmt.Sprintf(patternFormatStr, newVersion)
+
+	matcher := file_line_matcher.FileLineMatcher{}
+	numLines, err := matcher.MatchNumLines(toUpdateFilepath, searchPatternRegex)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "An error occurred while trying to count the number of occurrences of '%s' in '%s'", searchPatternStr, toUpdateFilepath)
+	}
+	if numLines != expectedNumSearchPatternLines {
+		return nil, stacktrace.NewError("An incorrect amount, '%d' of lines matching '%s' was found in '%s'. '%d' matching lines were expected.", numLines, searchPatternStr, toUpdateFilepath, expectedNumSearchPatternLines)
+	}
+
+	// TODO This reads a file of arbitrary size into memory, file should be updated via streaming via Scanner instead
+	updatedFileBytes := replaceLinesMatchingPattern(fileToUpdateBytes, searchPatternRegex, replaceValue)
+
+	return &VersionFileUpdate{
+		filepath:         toUpdateFilepath,
+		fileMode:         fileToUpdateInfo.Mode(),
+		updatedFileBytes: updatedFileBytes,
+	}, nil
+}
+
+// Apply writes the updated content to the file
+func (update *VersionFileUpdate) Apply() error {
+	if err := os.WriteFile(update.filepath, update.updatedFileBytes, update.fileMode); err != nil {
+		return stacktrace.Propagate(err, "An error occurred attempting to right the updated file contents to '%s'", update.filepath)
+	}
+	return nil
+}
+
+// ====================================================================================================
+//
+//	Private Helper Functions
+//
+// ====================================================================================================
+func replaceLinesMatchingPattern(file []byte, regexPat *regexp.Regexp, replacement string) []byte {
+	return regexPat.ReplaceAll(file, []byte(replacement))
+}
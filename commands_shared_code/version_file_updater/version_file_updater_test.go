@@ -1,15 +1,17 @@
-package updateversioninfile
+package version_file_updater
 
 import (
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"os"
+	"path"
 	"regexp"
 	"testing"
 )
 
 func TestNoMatchingPatternFoundReturnsIdenticalFile(t *testing.T) {
 	replacementStr := "KURTOSIS_CORE_VERSION: string = \"0.1.3\""
-	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", versionRegexStr)
+	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", VersionRegexStr)
 	searchPatternRegex := regexp.MustCompile(searchPatternStr)
 
 	fileWithNoMatchingPattern :=
@@ -25,7 +27,7 @@ func TestNoMatchingPatternFoundReturnsIdenticalFile(t *testing.T) {
 
 func TestMatchingPatternFoundReturnsUpdatedLine(t *testing.T) {
 	replacementStr := "KURTOSIS_CORE_VERSION: string = \"0.1.3\""
-	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", versionRegexStr)
+	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", VersionRegexStr)
 	searchPatternRegex := regexp.MustCompile(searchPatternStr)
 
 	fileWithMatchingPattern :=
@@ -47,7 +49,7 @@ KURTOSIS_CORE_VERSION: string = "0.1.3"
 
 func TestMultipleMatchingPatternsFoundReturnsUpdatedLines(t *testing.T) {
 	replacementStr := "KURTOSIS_CORE_VERSION: string = \"0.1.3\""
-	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", versionRegexStr)
+	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", VersionRegexStr)
 	searchPatternRegex := regexp.MustCompile(searchPatternStr)
 
 	fileWithMultipleLinesMatchingPattern :=
@@ -71,7 +73,7 @@ KURTOSIS_CORE_VERSION: string = "0.1.3"`
 
 func TestMatchingPatternsFoundUpdatesOnlyPattern(t *testing.T) {
 	replacementStr := "KURTOSIS_CORE_VERSION: string = \"0.1.3\""
-	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", versionRegexStr)
+	searchPatternStr := fmt.Sprintf("KURTOSIS_CORE_VERSION: string = \"%s\"", VersionRegexStr)
 	searchPatternRegex := regexp.MustCompile(searchPatternStr)
 
 	fileWithCommentOnLineMatchingPattern :=
@@ -95,7 +97,7 @@ func TestVersionRegexPattern(t *testing.T) {
 	validStrings := []string{"1.2.3", "tedisVersion", "10234-dirty", "1-2-3", "%thisTypeOfVersion"}
 	invalidStrings := []string{"#%^", " ", "", ""}
 
-	testRegexPattern(t, "Version Regex", versionRegexStr, validStrings, invalidStrings)
+	testRegexPattern(t, "Version Regex", VersionRegexStr, validStrings, invalidStrings)
 }
 
 func testRegexPattern(t *testing.T, regexPatternName string, regexPatternStr string, validStrings []string, invalidStrings []string) {
@@ -111,3 +113,24 @@ func testRegexPattern(t *testing.T, regexPatternName string, regexPatternStr str
 		require.False(t, patternDetected, "%s Pattern was detected in this string when it should not have been: '%s'.", regexPatternName, str)
 	}
 }
+
+func TestPrepareUpdateOnlyWritesOnApply(t *testing.T) {
+	filepath := path.Join(t.TempDir(), "version.txt")
+	require.NoError(t, os.WriteFile(filepath, []byte("# The version\nVERSION=1.5.2\n"), 0644))
+
+	update, err := PrepareUpdate(filepath, "VERSION=%s", "1.6.0")
+	require.NoError(t, err)
+	fileBytes, err := os.ReadFile(filepath)
+	require.NoError(t, err)
+	require.Equal(t, "# The version\nVERSION=1.5.2\n", string(fileBytes), "Preparing an update shouldn't change the file")
+
+	require.NoError(t, update.Apply())
+	fileBytes, err = os.ReadFile(filepath)
+	require.NoError(t, err)
+	require.Equal(t, "# The version\nVERSION=1.6.0\n", string(fileBytes))
+
+	_, err = PrepareUpdate(filepath, "MISSING=%s", "1.6.0")
+	require.Error(t, err, "A pattern matching no line should be rejected")
+	_, err = PrepareUpdate(filepath, "VERSION", "1.6.0")
+	require.Error(t, err, "A pattern without '%s' should be rejected")
+}